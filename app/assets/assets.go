@@ -1,7 +1,7 @@
 package assets
 
 import (
-	_ "embed"
+	"embed"
 	_ "image/png"
 )
 
@@ -14,4 +14,7 @@ var (
 
 	//go:embed sprites/walk.png
 	WalkSpriteSheet []byte
+
+	//go:embed prefabs/*.prefab.json
+	Prefabs embed.FS
 )
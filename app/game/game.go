@@ -2,23 +2,26 @@ package game
 
 import (
 	"log"
-	"reflect"
+	"time"
 
+	"github.com/Djosar/kro-ecs/app/assets"
 	"github.com/Djosar/kro-ecs/app/factories"
+	"github.com/Djosar/kro-ecs/lib/components"
 	"github.com/Djosar/kro-ecs/lib/core"
+	"github.com/Djosar/kro-ecs/lib/input"
+	"github.com/Djosar/kro-ecs/lib/resources"
 	"github.com/Djosar/kro-ecs/lib/systems"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // Game represents the main game structure. It holds the registry of all entities
-// and systems, and the player entity.
+// and systems. The player entity is marked with the PlayerTag.
 type Game struct {
-	Registry     *core.Registry
-	PlayerEntity core.Entity
+	Registry *core.Registry
 }
 
 // NewGame initializes and returns a new Game instance. It sets up the registry,
-// adds systems to it, and creates the player entity.
+// adds systems to it, loads the prefabs, and creates the player entity.
 //
 // Returns:
 //
@@ -31,40 +34,125 @@ func NewGame() (*Game, error) {
 		Registry: registry,
 	}
 
-	systems := []core.System{
-		systems.NewRenderSystem(),
-		systems.NewMovementSystem(),
+	// Controls are toggled off and on around cutscenes, so keep them out of the archetypes
+	if err := core.RegisterComponent[*components.ControlsComponent](game.Registry, core.StorageSparseSet); err != nil {
+		return nil, err
+	}
+
+	core.InsertResource(game.Registry, &resources.Time{})
+
+	// Animation frames are owned by their component, so release them with it,
+	// including when the component is replaced by another one
+	core.OnRemove(game.Registry, func(_ *core.Registry, _ core.Entity, animation *components.AnimationComponent) {
+		animation.Deallocate()
+	})
+	core.OnReplace(game.Registry, func(_ *core.Registry, _ core.Entity, previous, animation *components.AnimationComponent) {
+		if previous != animation {
+			previous.Deallocate()
+		}
+	})
+
+	if err := game.Registry.AddSystem(
+		"input",
 		systems.NewInputSystem(),
+		core.InPhase(core.PhasePreUpdate),
+		core.Writes[*components.ControlsComponent](),
+	); err != nil {
+		return nil, err
+	}
+	if err := game.Registry.AddSystem(
+		"movement",
+		systems.NewMovementSystem(),
+		core.InPhase(core.PhaseFixedUpdate),
+		core.Reads[*components.ControlsComponent](),
+		core.Writes[*components.TransformComponent](),
+	); err != nil {
+		return nil, err
+	}
+	if err := game.Registry.AddSystem(
+		"animation",
 		systems.NewAnimationSystem(),
+		core.InPhase(core.PhaseUpdate),
+		core.Reads[*components.TransformComponent](),
+		core.Writes[*components.AnimationComponent](),
+	); err != nil {
+		return nil, err
+	}
+	if err := game.Registry.AddSystem(
+		"transform-propagation",
+		systems.NewTransformPropagationSystem(),
+		core.InPhase(core.PhasePostUpdate),
+		core.Reads[*components.TransformComponent](),
+		core.Reads[core.Parent](),
+		core.Reads[core.Children](),
+		core.Writes[*components.GlobalTransformComponent](),
+	); err != nil {
+		return nil, err
+	}
+	if err := game.Registry.AddRenderer("world", systems.NewRenderSystem()); err != nil {
+		return nil, err
 	}
-	for _, system := range systems {
-		game.Registry.AddSystem(system)
+	if err := game.Registry.BuildSchedule(); err != nil {
+		return nil, err
 	}
 
-	entity, err := factories.PlayableCharacterFactory(game.Registry)
+	types, err := NewSerializableTypes()
 	if err != nil {
+		return nil, err
+	}
+	prefabs := core.NewPrefabLibrary(types)
+	if err := prefabs.LoadFS(assets.Prefabs, "prefabs/*.prefab.json"); err != nil {
+		return nil, err
+	}
+
+	if _, err := factories.PlayableCharacterFactory(game.Registry, prefabs); err != nil {
 		log.Fatal(err)
 		return nil, err
 	}
-	game.PlayerEntity = entity
 
 	return game, nil
 }
 
+// RecordInput starts recording the keys held down during every tick.
+//
+// Returns:
+//
+//	*input.Recording: The recording, which grows while the game runs.
+func (g *Game) RecordInput() *input.Recording {
+	recording := input.NewRecording()
+	g.Registry.GetSystem("input").(*systems.InputSystem).Recording = recording
+	return recording
+}
+
+// ReplayInput replaces the keyboard with a recording, reproducing the recorded
+// session tick by tick.
+//
+// Parameters:
+//
+//	recording (*input.Recording): The recording to replay.
+func (g *Game) ReplayInput(recording *input.Recording) {
+	g.Registry.GetSystem("input").(*systems.InputSystem).Source = input.NewReplaySource(recording)
+}
+
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
 	return 640, 480
 }
 
 func (g *Game) Update() error {
-	excludedTypes := []reflect.Type{
-		reflect.TypeOf(&systems.RenderSystem{}),
+	if clock, ok := core.Resource[*resources.Time](g.Registry); ok {
+		clock.Advance(time.Second / time.Duration(ebiten.TPS()))
+	}
+	g.Registry.AdvanceFrame()
+	for _, phase := range core.UpdatePhases {
+		if err := g.Registry.RunPhase(phase); err != nil {
+			return err
+		}
 	}
-	g.Registry.UpdateSystems(excludedTypes)
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	renderer := g.Registry.GetSystem(reflect.TypeOf(&systems.RenderSystem{}))
-	renderer.(*systems.RenderSystem).Screen = screen
-	renderer.Update(g.Registry)
+	if err := g.Registry.RunRenderPhase(screen); err != nil {
+		log.Fatal(err)
+	}
 }
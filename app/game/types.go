@@ -0,0 +1,31 @@
+package game
+
+import (
+	"github.com/Djosar/kro-ecs/lib/components"
+	"github.com/Djosar/kro-ecs/lib/core"
+	"github.com/Djosar/kro-ecs/lib/resources"
+)
+
+// NewSerializableTypes registers the component and resource types of the game
+// under the names used by prefabs and saves.
+//
+// Returns:
+//
+//	*core.SerializableTypes: The registered types.
+//	error: An error if a type cannot be registered.
+func NewSerializableTypes() (*core.SerializableTypes, error) {
+	types := core.NewSerializableTypes()
+	if err := core.RegisterSerializable[*components.TransformComponent](types, "transform"); err != nil {
+		return nil, err
+	}
+	if err := core.RegisterSerializable[*components.ControlsComponent](types, "controls"); err != nil {
+		return nil, err
+	}
+	if err := core.RegisterSerializable[components.PlayerTag](types, "player"); err != nil {
+		return nil, err
+	}
+	if err := core.RegisterSerializable[*resources.Time](types, "time"); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
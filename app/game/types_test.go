@@ -0,0 +1,51 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Djosar/kro-ecs/lib/components"
+	"github.com/Djosar/kro-ecs/lib/core"
+	"github.com/Djosar/kro-ecs/lib/resources"
+	"github.com/Djosar/kro-ecs/lib/util"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestGameWorldBinaryRoundTrip checks that every type registered by
+// NewSerializableTypes survives a binary save.
+func TestGameWorldBinaryRoundTrip(t *testing.T) {
+	types, err := NewSerializableTypes()
+	if err != nil {
+		t.Fatalf("NewSerializableTypes() error = %v", err)
+	}
+	source := core.NewRegistry()
+	player := source.NewEntity()
+	core.Add(source, player, &components.TransformComponent{Speed: 1, Direction: "left", Position: util.Coordinate[float32]{X: 3, Y: 4}})
+	core.Add(source, player, &components.ControlsComponent{Bindings: map[ebiten.Key]string{ebiten.KeyA: "move-left"}})
+	core.Add(source, player, components.PlayerTag{})
+	core.InsertResource(source, &resources.Time{Frame: 9})
+
+	var buffer bytes.Buffer
+	if err := source.SaveBinary(&buffer, types); err != nil {
+		t.Fatalf("SaveBinary() error = %v", err)
+	}
+	destination := core.NewRegistry()
+	mapping, err := destination.LoadBinary(&buffer, types)
+	if err != nil {
+		t.Fatalf("LoadBinary() error = %v", err)
+	}
+
+	loaded := mapping[player]
+	if transform, ok := core.Get[*components.TransformComponent](destination, loaded); !ok || transform.Position.X != 3 || transform.Direction != "left" {
+		t.Errorf("TransformComponent = %+v, %v, want the saved transform", transform, ok)
+	}
+	if controls, ok := core.Get[*components.ControlsComponent](destination, loaded); !ok || controls.Controls[ebiten.KeyA] == nil {
+		t.Errorf("ControlsComponent = %+v, %v, want the A key bound", controls, ok)
+	}
+	if !core.Has[components.PlayerTag](destination, loaded) {
+		t.Error("loaded player lost its PlayerTag")
+	}
+	if time, ok := core.Resource[*resources.Time](destination); !ok || time.Frame != 9 {
+		t.Errorf("Time = %+v, %v, want Frame 9", time, ok)
+	}
+}
@@ -13,3 +13,13 @@ type AnimationComponent struct {
 func (ac *AnimationComponent) GetCurrentAnimation() *util.Animation {
 	return ac.Animations[ac.CurrentAnimation]
 }
+
+// Deallocate releases the frames of all animations. The component must not be
+// drawn afterwards, which includes copies of it restored by a rollback.
+func (ac *AnimationComponent) Deallocate() {
+	for _, animation := range ac.Animations {
+		for _, frame := range animation.Frames {
+			frame.Deallocate()
+		}
+	}
+}
@@ -1,10 +1,153 @@
 package components
 
 import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type ControlsComponent struct {
-	Controls       map[ebiten.Key]func(*TransformComponent)
+	Controls       ControlMap `json:"-"`
+	Bindings       map[ebiten.Key]string
 	ControlsBuffer []ebiten.Key
 }
+
+// ControlMap maps keys to the functions applied to the transform while the key is
+// pressed.
+type ControlMap map[ebiten.Key]func(*TransformComponent)
+
+// GobEncode encodes nothing: gob describes every field of a type even if the type
+// encodes itself, and functions cannot be described. ControlsComponent rebuilds
+// its Controls from Bindings instead.
+//
+// Returns:
+//
+//	[]byte: An empty encoding.
+//	error: Always nil.
+func (cm ControlMap) GobEncode() ([]byte, error) {
+	return nil, nil
+}
+
+// GobDecode decodes the empty encoding written by GobEncode.
+//
+// Parameters:
+//
+//	data ([]byte): The encoding, which is ignored.
+//
+// Returns:
+//
+//	error: Always nil.
+func (cm *ControlMap) GobDecode(data []byte) error {
+	return nil
+}
+
+// ControlActions maps the action names used in Bindings to the functions applied
+// to the transform while the bound key is pressed.
+var ControlActions = map[string]func(*TransformComponent){
+	"move-up": func(transformComponent *TransformComponent) {
+		transformComponent.Velocity.DY = -1
+		transformComponent.Direction = "up"
+	},
+	"move-right": func(transformComponent *TransformComponent) {
+		transformComponent.Velocity.DX = 1
+		transformComponent.Direction = "right"
+	},
+	"move-down": func(transformComponent *TransformComponent) {
+		transformComponent.Velocity.DY = 1
+		transformComponent.Direction = "down"
+	},
+	"move-left": func(transformComponent *TransformComponent) {
+		transformComponent.Velocity.DX = -1
+		transformComponent.Direction = "left"
+	},
+	"sprint": func(transformComponent *TransformComponent) { transformComponent.Speed = 2 },
+}
+
+// UnmarshalJSON decodes the component and resolves the Bindings, which map key
+// names to action names such as {"W": "move-up"}, into Controls.
+//
+// Parameters:
+//
+//	data ([]byte): The JSON encoding of the component.
+//
+// Returns:
+//
+//	error: An error if the data is malformed or names an unknown key or action.
+func (cc *ControlsComponent) UnmarshalJSON(data []byte) error {
+	type plain ControlsComponent
+	if err := json.Unmarshal(data, (*plain)(cc)); err != nil {
+		return err
+	}
+	return cc.resolveControls()
+}
+
+// controlsState is the part of a ControlsComponent that is encoded in binary
+// saves; Controls holds functions and is rebuilt from Bindings instead.
+type controlsState struct {
+	Bindings       map[ebiten.Key]string
+	ControlsBuffer []ebiten.Key
+}
+
+// GobEncode encodes the Bindings and ControlsBuffer of the component for binary
+// saves.
+//
+// Returns:
+//
+//	[]byte: The gob encoding of the component.
+//	error: An error if the component cannot be encoded.
+func (cc *ControlsComponent) GobEncode() ([]byte, error) {
+	var buffer bytes.Buffer
+	err := gob.NewEncoder(&buffer).Encode(controlsState{Bindings: cc.Bindings, ControlsBuffer: cc.ControlsBuffer})
+	return buffer.Bytes(), err
+}
+
+// GobDecode decodes a component written by GobEncode and resolves its Bindings
+// into Controls like UnmarshalJSON.
+//
+// Parameters:
+//
+//	data ([]byte): The gob encoding of the component.
+//
+// Returns:
+//
+//	error: An error if the data is malformed or names an unknown action.
+func (cc *ControlsComponent) GobDecode(data []byte) error {
+	var state controlsState
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	cc.Bindings, cc.ControlsBuffer = state.Bindings, state.ControlsBuffer
+	return cc.resolveControls()
+}
+
+// resolveControls rebuilds Controls from the action names in Bindings.
+//
+// Returns:
+//
+//	error: An error if a binding names an unknown action.
+func (cc *ControlsComponent) resolveControls() error {
+	cc.Controls = make(ControlMap, len(cc.Bindings))
+	for key, action := range cc.Bindings {
+		control, ok := ControlActions[action]
+		if !ok {
+			return fmt.Errorf("components: unknown control action %q", action)
+		}
+		cc.Controls[key] = control
+	}
+	return nil
+}
+
+// Clone copies the component including its ControlsBuffer, which the InputSystem
+// modifies in place, so that world snapshots are not affected by later input.
+//
+// Returns:
+//
+//	interface{}: The copy.
+func (cc *ControlsComponent) Clone() interface{} {
+	clone := *cc
+	clone.ControlsBuffer = append([]ebiten.Key(nil), cc.ControlsBuffer...)
+	return &clone
+}
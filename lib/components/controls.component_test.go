@@ -0,0 +1,75 @@
+package components
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/Djosar/kro-ecs/lib/core"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestControlsBinaryRoundTrip checks that a ControlsComponent survives a binary
+// save and that the loaded component resolves its Bindings into Controls, so that
+// a loaded entity can still move.
+func TestControlsBinaryRoundTrip(t *testing.T) {
+	types := core.NewSerializableTypes()
+	if err := core.RegisterSerializable[*ControlsComponent](types, "controls"); err != nil {
+		t.Fatalf("RegisterSerializable() error = %v", err)
+	}
+	source := core.NewRegistry()
+	entity := source.NewEntity()
+	core.Add(source, entity, &ControlsComponent{
+		Controls: map[ebiten.Key]func(*TransformComponent){
+			ebiten.KeyD: ControlActions["move-right"],
+		},
+		Bindings:       map[ebiten.Key]string{ebiten.KeyD: "move-right", ebiten.KeyShift: "sprint"},
+		ControlsBuffer: []ebiten.Key{ebiten.KeyD},
+	})
+
+	var buffer bytes.Buffer
+	if err := source.SaveBinary(&buffer, types); err != nil {
+		t.Fatalf("SaveBinary() error = %v", err)
+	}
+	destination := core.NewRegistry()
+	mapping, err := destination.LoadBinary(&buffer, types)
+	if err != nil {
+		t.Fatalf("LoadBinary() error = %v", err)
+	}
+	loaded, ok := core.Get[*ControlsComponent](destination, mapping[entity])
+	if !ok {
+		t.Fatal("loaded entity has no ControlsComponent")
+	}
+	if want := map[ebiten.Key]string{ebiten.KeyD: "move-right", ebiten.KeyShift: "sprint"}; !reflect.DeepEqual(loaded.Bindings, want) {
+		t.Errorf("Bindings = %v, want %v", loaded.Bindings, want)
+	}
+	if want := []ebiten.Key{ebiten.KeyD}; !reflect.DeepEqual(loaded.ControlsBuffer, want) {
+		t.Errorf("ControlsBuffer = %v, want %v", loaded.ControlsBuffer, want)
+	}
+
+	var transform TransformComponent
+	for _, key := range []ebiten.Key{ebiten.KeyD, ebiten.KeyShift} {
+		control := loaded.Controls[key]
+		if control == nil {
+			t.Fatalf("Controls[%v] = nil, want the bound action", key)
+		}
+		control(&transform)
+	}
+	if transform.Velocity.DX != 1 || transform.Speed != 2 {
+		t.Errorf("resolved controls set velocity %v and speed %v, want DX 1 and speed 2", transform.Velocity, transform.Speed)
+	}
+}
+
+// TestControlsGobDecodeUnknownAction checks that decoding rejects a binding to an
+// unknown action like UnmarshalJSON.
+func TestControlsGobDecodeUnknownAction(t *testing.T) {
+	data, err := (&ControlsComponent{Bindings: map[ebiten.Key]string{ebiten.KeyD: "teleport"}}).GobEncode()
+	if err != nil {
+		t.Fatalf("GobEncode() error = %v", err)
+	}
+	var decoded ControlsComponent
+	err = decoded.GobDecode(data)
+	if want := `components: unknown control action "teleport"`; err == nil || err.Error() != want {
+		t.Errorf("GobDecode() error = %v, want %s", err, want)
+	}
+}
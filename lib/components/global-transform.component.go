@@ -0,0 +1,10 @@
+package components
+
+import "github.com/Djosar/kro-ecs/lib/util"
+
+// GlobalTransformComponent holds the world-space position of an entity. It is
+// computed by the TransformPropagationSystem from the TransformComponent, whose
+// Position is relative to the parent entity, if any.
+type GlobalTransformComponent struct {
+	Position util.Coordinate[float32]
+}
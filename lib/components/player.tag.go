@@ -0,0 +1,4 @@
+package components
+
+// PlayerTag marks the entity controlled by the player.
+type PlayerTag struct{}
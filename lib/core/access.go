@@ -0,0 +1,153 @@
+package core
+
+import (
+	"slices"
+	"sync"
+)
+
+// Reads declares that a system reads components of type T. Systems that declare
+// their component access may run concurrently with other such systems whose
+// access does not conflict. Systems without declared access always run alone.
+//
+// Returns:
+//
+//	SystemOption: The option declaring the read access.
+func Reads[T any]() SystemOption {
+	identifier := componentType[T]()
+	return func(entry *systemEntry) {
+		entry.declared = true
+		entry.reads = append(entry.reads, identifier)
+	}
+}
+
+// Writes declares that a system writes components of type T. A writing system
+// never runs concurrently with another system reading or writing T.
+//
+// Returns:
+//
+//	SystemOption: The option declaring the write access.
+func Writes[T any]() SystemOption {
+	identifier := componentType[T]()
+	return func(entry *systemEntry) {
+		entry.declared = true
+		entry.writes = append(entry.writes, identifier)
+	}
+}
+
+// conflictsWith reports whether two systems may not run concurrently, either
+// because one of them has not declared its access or because one writes a
+// component type the other accesses.
+//
+// Parameters:
+//
+//	other (*systemEntry): The system to compare with.
+//
+// Returns:
+//
+//	bool: True if the systems must run one after the other.
+func (e *systemEntry) conflictsWith(other *systemEntry) bool {
+	if !e.declared || !other.declared {
+		return true
+	}
+	for _, identifier := range e.writes {
+		if slices.Contains(other.reads, identifier) || slices.Contains(other.writes, identifier) {
+			return true
+		}
+	}
+	for _, identifier := range other.writes {
+		if slices.Contains(e.reads, identifier) {
+			return true
+		}
+	}
+	return false
+}
+
+// dependsOn reports whether a system has a declared ordering constraint with another.
+//
+// Parameters:
+//
+//	other (*systemEntry): The system to compare with.
+//
+// Returns:
+//
+//	bool: True if either system must run before the other.
+func (e *systemEntry) dependsOn(other *systemEntry) bool {
+	return slices.Contains(e.after, other.label) || slices.Contains(e.before, other.label) ||
+		slices.Contains(other.after, e.label) || slices.Contains(other.before, e.label)
+}
+
+// buildStages groups consecutive systems of an execution order into stages whose
+// systems neither conflict nor depend on each other, so that each stage can run
+// concurrently.
+//
+// Parameters:
+//
+//	order ([]*systemEntry): The systems in execution order.
+//
+// Returns:
+//
+//	[][]*systemEntry: The stages in execution order.
+func buildStages(order []*systemEntry) [][]*systemEntry {
+	var stages [][]*systemEntry
+	var current []*systemEntry
+	for _, entry := range order {
+		for _, member := range current {
+			if entry.conflictsWith(member) || entry.dependsOn(member) {
+				stages = append(stages, current)
+				current = nil
+				break
+			}
+		}
+		current = append(current, entry)
+	}
+	if len(current) > 0 {
+		stages = append(stages, current)
+	}
+	return stages
+}
+
+// runStage updates the enabled systems of a stage, concurrently if there are
+// several, and flushes their commands in schedule order once all have returned.
+// The tick advances before the systems run and again before the flush, so that
+// the systems of the stage observe the changes made by their own commands on
+// their next run.
+//
+// Parameters:
+//
+//	stage ([]*systemEntry): The systems of the stage.
+func (r *Registry) runStage(stage []*systemEntry) {
+	var enabled []*systemEntry
+	for _, entry := range stage {
+		if !entry.disabled {
+			enabled = append(enabled, entry)
+		}
+	}
+
+	if len(enabled) == 0 {
+		return
+	}
+
+	r.tick++
+	switch len(enabled) {
+	case 1:
+		enabled[0].system.Update(r, enabled[0].commands)
+	default:
+		var group sync.WaitGroup
+		for _, entry := range enabled {
+			group.Add(1)
+			go func(entry *systemEntry) {
+				defer group.Done()
+				entry.system.Update(r, entry.commands)
+			}(entry)
+		}
+		group.Wait()
+	}
+
+	for _, entry := range enabled {
+		entry.commands.lastRun = r.tick
+	}
+	r.tick++
+	for _, entry := range enabled {
+		entry.commands.flush()
+	}
+}
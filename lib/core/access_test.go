@@ -0,0 +1,133 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// funcSystem adapts a function to the System interface.
+type funcSystem func(registry *Registry, commands *Commands)
+
+// Update calls the function.
+func (fs funcSystem) Update(registry *Registry, commands *Commands) {
+	fs(registry, commands)
+}
+
+// stageLabels returns the labels of the systems of every stage of PhaseUpdate.
+func stageLabels(t *testing.T, registry *Registry) [][]string {
+	t.Helper()
+	if err := registry.BuildSchedule(); err != nil {
+		t.Fatalf("BuildSchedule() error = %v", err)
+	}
+	var stages [][]string
+	for _, stage := range registry.phases[PhaseUpdate].stages {
+		var labels []string
+		for _, entry := range stage {
+			labels = append(labels, entry.label)
+		}
+		stages = append(stages, labels)
+	}
+	return stages
+}
+
+// TestStagesGroupNonConflictingSystems checks that declared systems without
+// conflicting access share a stage and run concurrently.
+func TestStagesGroupNonConflictingSystems(t *testing.T) {
+	registry := NewRegistry()
+	var started sync.WaitGroup
+	started.Add(2)
+	rendezvous := funcSystem(func(*Registry, *Commands) {
+		started.Done()
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Error("systems of the same stage did not run concurrently")
+		}
+	})
+	registry.AddSystem("positions", rendezvous, Writes[testPosition]())
+	registry.AddSystem("velocities", rendezvous, Writes[testVelocity](), Reads[testTag]())
+
+	if got, want := stageLabels(t, registry), [][]string{{"positions", "velocities"}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("stages = %v, want %v", got, want)
+	}
+	if err := registry.RunPhase(PhaseUpdate); err != nil {
+		t.Fatalf("RunPhase() error = %v", err)
+	}
+}
+
+// TestStagesSplitConflictingAndUndeclaredSystems checks that a write/read
+// conflict starts a new stage and that systems without declared access run alone.
+func TestStagesSplitConflictingAndUndeclaredSystems(t *testing.T) {
+	registry := NewRegistry()
+	var running, overlapped atomic.Int32
+	system := funcSystem(func(*Registry, *Commands) {
+		if running.Add(1) > 1 {
+			overlapped.Add(1)
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+	})
+	registry.AddSystem("write", system, Writes[testPosition]())
+	registry.AddSystem("read", system, Reads[testPosition]())
+	registry.AddSystem("undeclared", system)
+	registry.AddSystem("other", system, Reads[testVelocity]())
+
+	want := [][]string{{"write"}, {"read"}, {"undeclared"}, {"other"}}
+	if got := stageLabels(t, registry); !reflect.DeepEqual(got, want) {
+		t.Fatalf("stages = %v, want %v", got, want)
+	}
+	if err := registry.RunPhase(PhaseUpdate); err != nil {
+		t.Fatalf("RunPhase() error = %v", err)
+	}
+	if overlapped.Load() != 0 {
+		t.Error("systems of different stages overlapped")
+	}
+}
+
+// TestConcurrentSystemsSpawnThroughCommands checks that systems sharing a stage
+// may reserve entities through their Commands concurrently, and that the spawned
+// entities only become alive once the stage has run.
+func TestConcurrentSystemsSpawnThroughCommands(t *testing.T) {
+	registry := NewRegistry()
+	const spawns = 200
+	spawner := func(value float64) funcSystem {
+		return func(registry *Registry, commands *Commands) {
+			for i := 0; i < spawns; i++ {
+				entity := commands.Spawn()
+				if registry.IsAlive(entity) {
+					t.Error("spawned entity is alive before the flush")
+				}
+				AddDeferred(commands, entity, testPosition{X: value})
+			}
+		}
+	}
+	registry.AddSystem("left", spawner(-1), Reads[testVelocity]())
+	registry.AddSystem("right", spawner(1), Reads[testTag]())
+	if got := stageLabels(t, registry); len(got) != 1 {
+		t.Fatalf("stages = %v, want a single stage", got)
+	}
+
+	if err := registry.RunPhase(PhaseUpdate); err != nil {
+		t.Fatalf("RunPhase() error = %v", err)
+	}
+	counts := make(map[float64]int)
+	seen := make(map[uint32]bool)
+	Each(registry, func(entity Entity, position testPosition) {
+		counts[position.X]++
+		if seen[entity.Index()] {
+			t.Errorf("slot %d was reserved twice", entity.Index())
+		}
+		seen[entity.Index()] = true
+	})
+	if counts[-1] != spawns || counts[1] != spawns {
+		t.Errorf("spawned %v, want %d per system", counts, spawns)
+	}
+}
@@ -0,0 +1,337 @@
+package core
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// componentInfo describes a component type known to a registry. Components of
+// types using StorageSparseSet live in sparse instead of the archetypes.
+type componentInfo struct {
+	id         int
+	identifier reflect.Type
+	newColumn  func() column
+	storage    StorageKind
+	sparse     *sparseSet
+	hooks      *componentHooks
+}
+
+// archetype groups all entities that own exactly the same set of component types.
+// Each component type is stored in its own column with its change ticks, and row
+// i of every column belongs to entities[i]. Tag columns have nil ticks.
+type archetype struct {
+	components []*componentInfo
+	columns    []column
+	ticks      []*componentTicks
+	indices    map[int]int
+	entities   []Entity
+	addEdges   map[int]*archetype
+	dropEdges  map[int]*archetype
+}
+
+// entityRecord locates the components of an entity inside the archetype storage.
+type entityRecord struct {
+	archetype *archetype
+	row       int
+}
+
+// newArchetype creates an empty archetype for the given component types.
+//
+// Parameters:
+//
+//	components ([]*componentInfo): The component types, sorted by id.
+//
+// Returns:
+//
+//	*archetype: The newly created archetype.
+func newArchetype(components []*componentInfo) *archetype {
+	arch := &archetype{
+		components: components,
+		columns:    make([]column, len(components)),
+		ticks:      make([]*componentTicks, len(components)),
+		indices:    make(map[int]int, len(components)),
+		addEdges:   make(map[int]*archetype),
+		dropEdges:  make(map[int]*archetype),
+	}
+	for idx, info := range components {
+		arch.columns[idx] = info.newColumn()
+		arch.ticks[idx] = newComponentTicks(info)
+		arch.indices[info.id] = idx
+	}
+	return arch
+}
+
+// has reports whether the archetype contains the component type with the given id.
+//
+// Parameters:
+//
+//	id (int): The component id.
+//
+// Returns:
+//
+//	bool: True if the archetype contains the component type.
+func (a *archetype) has(id int) bool {
+	_, ok := a.indices[id]
+	return ok
+}
+
+// column returns the column storing the component type with the given id.
+//
+// Parameters:
+//
+//	id (int): The component id.
+//
+// Returns:
+//
+//	column: The column, or nil if the archetype does not contain the component type.
+func (a *archetype) column(id int) column {
+	if idx, ok := a.indices[id]; ok {
+		return a.columns[idx]
+	}
+	return nil
+}
+
+// columnTicks returns the change ticks of the component type with the given id.
+//
+// Parameters:
+//
+//	id (int): The component id.
+//
+// Returns:
+//
+//	*componentTicks: The ticks, or nil for tag types and if the archetype does not contain the component type.
+func (a *archetype) columnTicks(id int) *componentTicks {
+	if idx, ok := a.indices[id]; ok {
+		return a.ticks[idx]
+	}
+	return nil
+}
+
+// archetypeKey builds the lookup key of the archetype containing the given
+// component types.
+//
+// Parameters:
+//
+//	components ([]*componentInfo): The component types, sorted by id.
+//
+// Returns:
+//
+//	string: The lookup key.
+func archetypeKey(components []*componentInfo) string {
+	var key strings.Builder
+	for _, info := range components {
+		key.WriteString(strconv.Itoa(info.id))
+		key.WriteByte(',')
+	}
+	return key.String()
+}
+
+// componentInfo returns the info of a component type, or nil if the type has
+// never been added to the registry.
+//
+// Parameters:
+//
+//	identifier (reflect.Type): The component type.
+//
+// Returns:
+//
+//	*componentInfo: The info of the component type.
+func (r *Registry) componentInfo(identifier reflect.Type) *componentInfo {
+	return r.componentInfos[identifier]
+}
+
+// registerComponent returns the info of a component type, registering the type
+// with table storage on first use.
+//
+// Parameters:
+//
+//	identifier (reflect.Type): The component type.
+//	newColumn (func() column): Creates columns for the type; nil falls back to boxed columns.
+//
+// Returns:
+//
+//	*componentInfo: The info of the component type.
+func (r *Registry) registerComponent(identifier reflect.Type, newColumn func() column) *componentInfo {
+	return r.registerComponentStorage(identifier, newColumn, StorageTable)
+}
+
+// registerComponentStorage returns the info of a component type, registering the
+// type with the given storage on first use. Zero-size types are stored in tag
+// columns, which only count their rows.
+//
+// Parameters:
+//
+//	identifier (reflect.Type): The component type.
+//	newColumn (func() column): Creates columns for the type; nil falls back to boxed columns.
+//	storage (StorageKind): The storage used if the type is not registered yet.
+//
+// Returns:
+//
+//	*componentInfo: The info of the component type.
+func (r *Registry) registerComponentStorage(identifier reflect.Type, newColumn func() column, storage StorageKind) *componentInfo {
+	if info := r.componentInfos[identifier]; info != nil {
+		return info
+	}
+	if identifier.Size() == 0 {
+		newColumn = newTagColumn(reflect.Zero(identifier).Interface())
+	} else if newColumn == nil {
+		newColumn = newBoxedColumn
+	}
+	info := &componentInfo{
+		id:         len(r.componentInfos),
+		identifier: identifier,
+		newColumn:  newColumn,
+		storage:    storage,
+		hooks:      r.hooksOf(identifier),
+	}
+	if storage == StorageSparseSet {
+		info.sparse = newSparseSet(newColumn())
+		r.sparseInfos = append(r.sparseInfos, info)
+	}
+	r.componentInfos[identifier] = info
+	return info
+}
+
+// archetypeOf returns the archetype containing exactly the given component types,
+// creating it if it does not exist yet.
+//
+// Parameters:
+//
+//	components ([]*componentInfo): The component types, in any order.
+//
+// Returns:
+//
+//	*archetype: The archetype.
+func (r *Registry) archetypeOf(components []*componentInfo) *archetype {
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].id < components[j].id
+	})
+	key := archetypeKey(components)
+	if arch := r.archetypeIndex[key]; arch != nil {
+		return arch
+	}
+	arch := newArchetype(components)
+	r.archetypeIndex[key] = arch
+	r.archetypes = append(r.archetypes, arch)
+	return arch
+}
+
+// archetypeWith returns the archetype reached by adding a component type to source.
+//
+// Parameters:
+//
+//	source (*archetype): The archetype to extend.
+//	info (*componentInfo): The component type to add.
+//
+// Returns:
+//
+//	*archetype: The resulting archetype.
+func (r *Registry) archetypeWith(source *archetype, info *componentInfo) *archetype {
+	if target := source.addEdges[info.id]; target != nil {
+		return target
+	}
+	components := append(append([]*componentInfo{}, source.components...), info)
+	target := r.archetypeOf(components)
+	source.addEdges[info.id] = target
+	target.dropEdges[info.id] = source
+	return target
+}
+
+// archetypeWithout returns the archetype reached by removing a component type from source.
+//
+// Parameters:
+//
+//	source (*archetype): The archetype to reduce.
+//	info (*componentInfo): The component type to remove.
+//
+// Returns:
+//
+//	*archetype: The resulting archetype.
+func (r *Registry) archetypeWithout(source *archetype, info *componentInfo) *archetype {
+	if target := source.dropEdges[info.id]; target != nil {
+		return target
+	}
+	components := make([]*componentInfo, 0, len(source.components))
+	for _, other := range source.components {
+		if other.id != info.id {
+			components = append(components, other)
+		}
+	}
+	target := r.archetypeOf(components)
+	source.dropEdges[info.id] = target
+	target.addEdges[info.id] = source
+	return target
+}
+
+// moveEntity moves an entity and the components it shares with target into the
+// target archetype. Columns of target the entity had no component for are left
+// for the caller to fill.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to move.
+//	target (*archetype): The archetype to move the entity into.
+func (r *Registry) moveEntity(entity Entity, target *archetype) {
+	record := &r.records[entity.Index()]
+	source, row := record.archetype, record.row
+	for idx, info := range target.components {
+		if sourceColumn := source.column(info.id); sourceColumn != nil {
+			target.columns[idx].pushFrom(sourceColumn, row)
+			target.ticks[idx].pushFrom(source.columnTicks(info.id), row)
+		}
+	}
+	target.entities = append(target.entities, entity)
+	r.removeRow(source, row)
+	record.archetype = target
+	record.row = len(target.entities) - 1
+}
+
+// removeRow removes a row from an archetype by moving its last row into its place.
+//
+// Parameters:
+//
+//	arch (*archetype): The archetype to remove the row from.
+//	row (int): The row to remove.
+func (r *Registry) removeRow(arch *archetype, row int) {
+	last := len(arch.entities) - 1
+	if row != last {
+		moved := arch.entities[last]
+		arch.entities[row] = moved
+		r.records[moved.Index()].row = row
+	}
+	arch.entities = arch.entities[:last]
+	for idx, col := range arch.columns {
+		col.swapRemove(row)
+		arch.ticks[idx].swapRemove(row)
+	}
+}
+
+// locate returns the column, change ticks and row holding an entity's component
+// of the given type.
+//
+// Parameters:
+//
+//	identifier (reflect.Type): The component type.
+//	entity (Entity): The entity owning the component.
+//
+// Returns:
+//
+//	column: The column, or nil if the entity is not alive or has no such component.
+//	*componentTicks: The change ticks of the column.
+//	int: The row of the component within the column.
+func (r *Registry) locate(identifier reflect.Type, entity Entity) (column, *componentTicks, int) {
+	info := r.componentInfos[identifier]
+	if info == nil || !r.IsAlive(entity) {
+		return nil, nil, 0
+	}
+	if info.sparse != nil {
+		if row, ok := info.sparse.row(entity); ok {
+			return info.sparse.data, &info.sparse.ticks, row
+		}
+		return nil, nil, 0
+	}
+	record := r.records[entity.Index()]
+	return record.archetype.column(info.id), record.archetype.columnTicks(info.id), record.row
+}
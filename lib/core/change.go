@@ -0,0 +1,207 @@
+package core
+
+import "reflect"
+
+// Tick is a point in the registry's change history. The registry advances its
+// tick every time a stage of systems runs, and stamps components with the tick at
+// which they were added and last changed.
+type Tick uint32
+
+// componentTicks records, per row of a column, the ticks at which the component
+// was added and last changed.
+type componentTicks struct {
+	added   []Tick
+	changed []Tick
+}
+
+// newComponentTicks creates the change ticks of an archetype column. Columns of
+// zero-size tag types do not track ticks, so that tags cost no storage per entity.
+//
+// Parameters:
+//
+//	info (*componentInfo): The component type of the column.
+//
+// Returns:
+//
+//	*componentTicks: The empty ticks, or nil for tag types.
+func newComponentTicks(info *componentInfo) *componentTicks {
+	if info.identifier.Size() == 0 {
+		return nil
+	}
+	return &componentTicks{}
+}
+
+// push appends the ticks of a newly added component. Like every method of
+// componentTicks, it does nothing on the nil ticks of tag columns.
+//
+// Parameters:
+//
+//	tick (Tick): The tick at which the component was added.
+func (t *componentTicks) push(tick Tick) {
+	t.pushTicks(tick, tick)
+}
+
+// pushTicks appends the ticks of a component that was added and last changed at
+// different ticks, e.g. one restored from a snapshot.
+//
+// Parameters:
+//
+//	added (Tick): The tick at which the component was added.
+//	changed (Tick): The tick at which the component last changed.
+func (t *componentTicks) pushTicks(added, changed Tick) {
+	if t == nil {
+		return
+	}
+	t.added = append(t.added, added)
+	t.changed = append(t.changed, changed)
+}
+
+// pushFrom appends the ticks of a row of another column.
+//
+// Parameters:
+//
+//	source (*componentTicks): The ticks to copy from.
+//	row (int): The row to copy.
+func (t *componentTicks) pushFrom(source *componentTicks, row int) {
+	if t == nil {
+		return
+	}
+	t.added = append(t.added, source.added[row])
+	t.changed = append(t.changed, source.changed[row])
+}
+
+// swapRemove removes a row by moving the last row into its place.
+//
+// Parameters:
+//
+//	row (int): The row to remove.
+func (t *componentTicks) swapRemove(row int) {
+	if t == nil {
+		return
+	}
+	last := len(t.added) - 1
+	t.added[row], t.changed[row] = t.added[last], t.changed[last]
+	t.added, t.changed = t.added[:last], t.changed[:last]
+}
+
+// removal records that an entity lost a component at a given tick.
+type removal struct {
+	entity Entity
+	tick   Tick
+}
+
+// ChangeTick returns the current tick of the registry. Systems compare against
+// Commands.LastRun; code running outside of the schedule can store ChangeTick
+// and pass it to Added, Changed and Removed later on instead.
+//
+// Returns:
+//
+//	Tick: The current tick.
+func (r *Registry) ChangeTick() Tick {
+	return r.tick
+}
+
+// Added creates a filter matching only entities whose component of type T was
+// added after the given tick. Tags stored in archetype tables do not track ticks
+// and never match.
+//
+// Parameters:
+//
+//	since (Tick): The tick after which the component must have been added.
+//
+// Returns:
+//
+//	QueryFilter: The filter requiring a recently added component of type T.
+func Added[T any](since Tick) QueryFilter {
+	return QueryFilter{identifier: componentType[T](), kind: filterAdded, since: since}
+}
+
+// Changed creates a filter matching only entities whose component of type T was
+// added or marked as changed after the given tick. Tags stored in archetype tables
+// do not track ticks and never match.
+//
+// Parameters:
+//
+//	since (Tick): The tick after which the component must have changed.
+//
+// Returns:
+//
+//	QueryFilter: The filter requiring a recently changed component of type T.
+func Changed[T any](since Tick) QueryFilter {
+	return QueryFilter{identifier: componentType[T](), kind: filterChanged, since: since}
+}
+
+// MarkChanged marks the component of type T of an entity as changed at the
+// current tick. Components handed out as pointers are mutated in place, so
+// systems call MarkChanged after modifying them. Replacing a component with Add
+// marks it as changed as well. Tags do not track ticks and are left unmarked.
+// MarkChanged only writes the tick of the entity's own row, so it may be called
+// for the visited entity inside ParallelForEach.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	entity (Entity): The entity whose component changed.
+//
+// Returns:
+//
+//	bool: True if the entity owns a component of type T.
+func MarkChanged[T any](registry *Registry, entity Entity) bool {
+	col, ticks, row := registry.locate(componentType[T](), entity)
+	if col == nil {
+		return false
+	}
+	if ticks != nil {
+		ticks.changed[row] = registry.tick
+	}
+	return true
+}
+
+// Removed returns the entities that lost their component of type T, either by
+// removal or by being destroyed, after the given tick. Removals are retained
+// until the end of the frame following the one they happened in, where a frame
+// starts with every call to Registry.AdvanceFrame.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to inspect.
+//	since (Tick): The tick after which the component must have been removed.
+//
+// Returns:
+//
+//	[]Entity: The entities in removal order.
+func Removed[T any](registry *Registry, since Tick) []Entity {
+	var entities []Entity
+	for _, removed := range registry.removed[componentType[T]()] {
+		if removed.tick > since {
+			entities = append(entities, removed.entity)
+		}
+	}
+	return entities
+}
+
+// recordRemoval remembers that an entity lost a component.
+//
+// Parameters:
+//
+//	identifier (reflect.Type): The type of the removed component.
+//	entity (Entity): The entity that lost the component.
+func (r *Registry) recordRemoval(identifier reflect.Type, entity Entity) {
+	r.removed[identifier] = append(r.removed[identifier], removal{entity: entity, tick: r.tick})
+}
+
+// pruneRemovals forgets removals that happened before the previous frame and
+// starts a new frame.
+func (r *Registry) pruneRemovals() {
+	cutoff := r.frameStart
+	r.frameStart = r.tick
+	for identifier, removals := range r.removed {
+		kept := removals[:0]
+		for _, removed := range removals {
+			if removed.tick > cutoff {
+				kept = append(kept, removed)
+			}
+		}
+		r.removed[identifier] = kept
+	}
+}
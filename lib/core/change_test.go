@@ -0,0 +1,145 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// changeLog is what a system observed through change filters during one run.
+type changeLog struct {
+	added   []Entity
+	changed []Entity
+	removed []Entity
+}
+
+// newChangeObserver registers a system logging the positions added, changed and
+// removed since its previous run, and returns the log of every run.
+func newChangeObserver(t *testing.T, registry *Registry) *[]changeLog {
+	t.Helper()
+	var runs []changeLog
+	observer := funcSystem(func(registry *Registry, commands *Commands) {
+		var run changeLog
+		NewQuery1[testPosition](registry, Added[testPosition](commands.LastRun())).Each(func(entity Entity, _ testPosition) {
+			run.added = append(run.added, entity)
+		})
+		NewQuery1[testPosition](registry, Changed[testPosition](commands.LastRun())).Each(func(entity Entity, _ testPosition) {
+			run.changed = append(run.changed, entity)
+		})
+		run.removed = Removed[testPosition](registry, commands.LastRun())
+		runs = append(runs, run)
+	})
+	if err := registry.AddSystem("observer", observer); err != nil {
+		t.Fatalf("AddSystem() error = %v", err)
+	}
+	return &runs
+}
+
+// runFrame advances the frame and runs PhaseUpdate.
+func runFrame(t *testing.T, registry *Registry) {
+	t.Helper()
+	registry.AdvanceFrame()
+	if err := registry.RunPhase(PhaseUpdate); err != nil {
+		t.Fatalf("RunPhase() error = %v", err)
+	}
+}
+
+// TestChangeFiltersSinceLastRun checks that Added, Changed and Removed report
+// every change exactly once to a system comparing against Commands.LastRun.
+func TestChangeFiltersSinceLastRun(t *testing.T) {
+	registry := NewRegistry()
+	runs := newChangeObserver(t, registry)
+
+	first := registry.NewEntity()
+	Add(registry, first, testPosition{})
+	runFrame(t, registry)
+
+	second := registry.NewEntity()
+	Add(registry, second, testPosition{})
+	MarkChanged[testPosition](registry, first)
+	runFrame(t, registry)
+
+	runFrame(t, registry)
+
+	Add(registry, second, testPosition{X: 1})
+	Remove[testPosition](registry, first)
+	runFrame(t, registry)
+
+	registry.DestroyEntity(second)
+	runFrame(t, registry)
+
+	want := []changeLog{
+		{added: []Entity{first}, changed: []Entity{first}},
+		{added: []Entity{second}, changed: []Entity{first, second}},
+		{},
+		{changed: []Entity{second}, removed: []Entity{first}},
+		{removed: []Entity{second}},
+	}
+	if !reflect.DeepEqual(*runs, want) {
+		t.Errorf("runs = %+v, want %+v", *runs, want)
+	}
+}
+
+// TestChangeFiltersSeeOtherSystems checks that a system observes components added
+// through the commands of a system running before it, and on its next run the
+// changes of systems running after it.
+func TestChangeFiltersSeeOtherSystems(t *testing.T) {
+	registry := NewRegistry()
+	runs := newChangeObserver(t, registry)
+	var spawned Entity
+	registry.AddSystem("spawner", funcSystem(func(registry *Registry, commands *Commands) {
+		if spawned == NilEntity {
+			spawned = commands.Spawn()
+			AddDeferred(commands, spawned, testPosition{})
+		}
+	}), Before("observer"))
+	var marked Entity
+	registry.AddSystem("marker", funcSystem(func(registry *Registry, commands *Commands) {
+		if marked == NilEntity {
+			marked = spawned
+			return
+		}
+		MarkChanged[testPosition](registry, marked)
+	}), After("observer"))
+
+	runFrame(t, registry)
+	runFrame(t, registry)
+	runFrame(t, registry)
+
+	want := []changeLog{
+		{added: []Entity{spawned}, changed: []Entity{spawned}},
+		{},
+		{changed: []Entity{spawned}},
+	}
+	if !reflect.DeepEqual(*runs, want) {
+		t.Errorf("runs = %+v, want %+v", *runs, want)
+	}
+}
+
+// TestRemovalsArePrunedByAdvanceFrame checks that removals are kept until the end
+// of the frame after the one they happened in, also in registries that never run
+// a phase.
+func TestRemovalsArePrunedByAdvanceFrame(t *testing.T) {
+	registry := NewRegistry()
+	entity := registry.NewEntity()
+	Add(registry, entity, testPosition{})
+	registry.DestroyEntity(entity)
+
+	registry.AdvanceFrame()
+	if got := Removed[testPosition](registry, 0); !reflect.DeepEqual(got, []Entity{entity}) {
+		t.Fatalf("Removed() in the next frame = %v, want [%v]", got, entity)
+	}
+	registry.AdvanceFrame()
+	if got := Removed[testPosition](registry, 0); len(got) != 0 {
+		t.Fatalf("Removed() two frames later = %v, want none", got)
+	}
+
+	for frame := 0; frame < 100; frame++ {
+		registry.AdvanceFrame()
+		entity := registry.NewEntity()
+		Add(registry, entity, testPosition{})
+		registry.DestroyEntity(entity)
+	}
+	if retained := len(registry.removed[componentType[testPosition]()]); retained > 2 {
+		t.Errorf("retained %d removals, want at most 2", retained)
+	}
+}
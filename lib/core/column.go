@@ -0,0 +1,171 @@
+package core
+
+// column stores the components of a single type for every entity of an
+// archetype, packed contiguously in row order.
+type column interface {
+	len() int
+	get(row int) Component
+	set(row int, component Component)
+	push(component Component)
+	pushFrom(source column, row int)
+	swapRemove(row int)
+	newEmpty() column
+}
+
+// typedColumn is a column backed by a slice of the component type itself. It is
+// used for every component type known at compile time through the generic API.
+type typedColumn[T any] struct {
+	data []T
+}
+
+// newTypedColumn creates an empty column for components of type T.
+//
+// Returns:
+//
+//	column: The newly created column.
+func newTypedColumn[T any]() column {
+	return &typedColumn[T]{}
+}
+
+func (c *typedColumn[T]) len() int {
+	return len(c.data)
+}
+
+func (c *typedColumn[T]) get(row int) Component {
+	return c.data[row]
+}
+
+func (c *typedColumn[T]) set(row int, component Component) {
+	c.data[row] = component.(T)
+}
+
+func (c *typedColumn[T]) push(component Component) {
+	c.data = append(c.data, component.(T))
+}
+
+func (c *typedColumn[T]) pushFrom(source column, row int) {
+	c.data = append(c.data, source.(*typedColumn[T]).data[row])
+}
+
+func (c *typedColumn[T]) swapRemove(row int) {
+	last := len(c.data) - 1
+	c.data[row] = c.data[last]
+	var zero T
+	c.data[last] = zero
+	c.data = c.data[:last]
+}
+
+func (c *typedColumn[T]) newEmpty() column {
+	return &typedColumn[T]{}
+}
+
+// boxedColumn is a column of interface values. It backs component types that were
+// first added through the non-generic AddComponent, where the static type is unknown.
+type boxedColumn struct {
+	data []Component
+}
+
+// newBoxedColumn creates an empty column of interface values.
+//
+// Returns:
+//
+//	column: The newly created column.
+func newBoxedColumn() column {
+	return &boxedColumn{}
+}
+
+func (c *boxedColumn) len() int {
+	return len(c.data)
+}
+
+func (c *boxedColumn) get(row int) Component {
+	return c.data[row]
+}
+
+func (c *boxedColumn) set(row int, component Component) {
+	c.data[row] = component
+}
+
+func (c *boxedColumn) push(component Component) {
+	c.data = append(c.data, component)
+}
+
+func (c *boxedColumn) pushFrom(source column, row int) {
+	c.data = append(c.data, source.get(row))
+}
+
+func (c *boxedColumn) swapRemove(row int) {
+	last := len(c.data) - 1
+	c.data[row] = c.data[last]
+	c.data[last] = nil
+	c.data = c.data[:last]
+}
+
+func (c *boxedColumn) newEmpty() column {
+	return &boxedColumn{}
+}
+
+// tagColumn is a column of a zero-size component type. All components of such a
+// type are equal, so the column only counts its rows.
+type tagColumn struct {
+	count int
+	tag   Component
+}
+
+// newTagColumn creates a factory of empty columns for a zero-size component type.
+//
+// Parameters:
+//
+//	tag (Component): The value of the component type.
+//
+// Returns:
+//
+//	func() column: The factory creating empty columns.
+func newTagColumn(tag Component) func() column {
+	return func() column {
+		return &tagColumn{tag: tag}
+	}
+}
+
+func (c *tagColumn) len() int {
+	return c.count
+}
+
+func (c *tagColumn) get(row int) Component {
+	return c.tag
+}
+
+func (c *tagColumn) set(row int, component Component) {}
+
+func (c *tagColumn) push(component Component) {
+	c.count++
+}
+
+func (c *tagColumn) pushFrom(source column, row int) {
+	c.count++
+}
+
+func (c *tagColumn) swapRemove(row int) {
+	c.count--
+}
+
+func (c *tagColumn) newEmpty() column {
+	return &tagColumn{tag: c.tag}
+}
+
+// columnValue returns the component stored in a row of a column as a T.
+//
+// Parameters:
+//
+//	source (column): The column to read.
+//	row (int): The row of the component.
+//
+// Returns:
+//
+//	T: The component.
+func columnValue[T any](source column, row int) T {
+	if typed, ok := source.(*typedColumn[T]); ok {
+		return typed.data[row]
+	}
+	return source.get(row).(T)
+}
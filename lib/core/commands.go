@@ -0,0 +1,142 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Commands records structural changes, such as spawning and despawning entities
+// or adding and removing components, so that they can be applied once no query is
+// iterating the registry. Every system receives its own Commands, which the
+// registry flushes once every system of the system's stage has run, in schedule
+// order. Commands may be recorded from several goroutines, e.g. from within
+// ParallelForEach.
+type Commands struct {
+	registry *Registry
+	mutex    sync.Mutex
+	queue    []func(registry *Registry)
+	lastRun  Tick
+}
+
+// newCommands creates an empty command buffer for a registry.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry the commands are applied to.
+//
+// Returns:
+//
+//	*Commands: The newly created command buffer.
+func newCommands(registry *Registry) *Commands {
+	return &Commands{registry: registry}
+}
+
+// LastRun returns the tick at which the system owning the buffer last ran, or 0
+// if it has not run before. Pass it to Added, Changed and Removed to process only
+// what changed since then.
+//
+// Returns:
+//
+//	Tick: The tick of the previous run.
+func (c *Commands) LastRun() Tick {
+	return c.lastRun
+}
+
+// Spawn reserves a new entity and records its creation. The returned entity can
+// be used in further commands right away, but is only alive once the commands
+// have been flushed.
+//
+// Returns:
+//
+//	Entity: The identifier of the entity to be created.
+func (c *Commands) Spawn() Entity {
+	entity := c.registry.reserveEntity()
+	c.record(func(registry *Registry) {
+		registry.spawnReserved(entity)
+	})
+	return entity
+}
+
+// Despawn records the destruction of an entity.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to be destroyed.
+func (c *Commands) Despawn(entity Entity) {
+	c.record(func(registry *Registry) {
+		registry.DestroyEntity(entity)
+	})
+}
+
+// AddComponent records adding a component to an entity. Nil components are
+// ignored. Prefer AddDeferred, which stores components in typed columns.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to which the component is added.
+//	component (Component): The component to be added.
+func (c *Commands) AddComponent(entity Entity, component Component) {
+	if component == nil {
+		return
+	}
+	c.record(func(registry *Registry) {
+		registry.AddComponent(entity, component)
+	})
+}
+
+// RemoveComponent records removing the component of a specified type from an entity.
+//
+// Parameters:
+//
+//	componentType (reflect.Type): The type of component to remove.
+//	entity (Entity): The entity whose component is to be removed.
+func (c *Commands) RemoveComponent(componentType reflect.Type, entity Entity) {
+	c.record(func(registry *Registry) {
+		registry.RemoveComponent(componentType, entity)
+	})
+}
+
+// AddDeferred records adding a component of type T to an entity.
+//
+// Parameters:
+//
+//	commands (*Commands): The command buffer to record into.
+//	entity (Entity): The entity to which the component is added.
+//	component (T): The component to be added.
+func AddDeferred[T any](commands *Commands, entity Entity, component T) {
+	commands.record(func(registry *Registry) {
+		Add(registry, entity, component)
+	})
+}
+
+// RemoveDeferred records removing the component of type T from an entity.
+//
+// Parameters:
+//
+//	commands (*Commands): The command buffer to record into.
+//	entity (Entity): The entity whose component is to be removed.
+func RemoveDeferred[T any](commands *Commands, entity Entity) {
+	commands.record(func(registry *Registry) {
+		Remove[T](registry, entity)
+	})
+}
+
+// record appends a command to the buffer.
+//
+// Parameters:
+//
+//	command (func(*Registry)): The command to apply when the buffer is flushed.
+func (c *Commands) record(command func(registry *Registry)) {
+	c.mutex.Lock()
+	c.queue = append(c.queue, command)
+	c.mutex.Unlock()
+}
+
+// flush applies all recorded commands in recording order and empties the buffer.
+func (c *Commands) flush() {
+	for idx, command := range c.queue {
+		command(c.registry)
+		c.queue[idx] = nil
+	}
+	c.queue = c.queue[:0]
+}
@@ -1,3 +1,109 @@
 package core
 
+import (
+	"fmt"
+	"reflect"
+)
+
 type Component = interface{}
+
+// componentType returns the reflect.Type used to identify components of type T
+// inside the registry.
+//
+// Returns:
+//
+//	reflect.Type: The type identifier of T.
+func componentType[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// RegisterComponent registers the component type T with the given storage. Types
+// are registered with StorageTable on first use, so RegisterComponent must be
+// called before the first component of type T is added.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to register the type with.
+//	storage (StorageKind): The storage used for components of type T.
+//
+// Returns:
+//
+//	error: An error if T is already registered with a different storage.
+func RegisterComponent[T any](registry *Registry, storage StorageKind) error {
+	info := registry.registerComponentStorage(componentType[T](), newTypedColumn[T], storage)
+	if info.storage != storage {
+		return fmt.Errorf("core: component %v is already registered with a different storage", info.identifier)
+	}
+	return nil
+}
+
+// Add adds a component of type T to the specified entity, replacing any
+// component of the same type the entity already owns.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	entity (Entity): The entity to which the component is added.
+//	component (T): The component to be added.
+func Add[T any](registry *Registry, entity Entity, component T) {
+	registry.addComponent(registry.registerComponent(componentType[T](), newTypedColumn[T]), entity, component)
+}
+
+// Get returns the component of type T owned by the specified entity.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	entity (Entity): The entity whose component is to be retrieved.
+//
+// Returns:
+//
+//	T: The component of type T, or the zero value of T if the entity has none.
+//	bool: True if the entity owns a component of type T.
+func Get[T any](registry *Registry, entity Entity) (T, bool) {
+	col, _, row := registry.locate(componentType[T](), entity)
+	if col == nil {
+		var zero T
+		return zero, false
+	}
+	return columnValue[T](col, row), true
+}
+
+// Has reports whether the specified entity owns a component of type T.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	entity (Entity): The entity to check.
+//
+// Returns:
+//
+//	bool: True if the entity owns a component of type T.
+func Has[T any](registry *Registry, entity Entity) bool {
+	col, _, _ := registry.locate(componentType[T](), entity)
+	return col != nil
+}
+
+// Remove removes the component of type T from the specified entity.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	entity (Entity): The entity whose component is to be removed.
+//
+// Returns:
+//
+//	bool: True if the entity owned a component of type T.
+func Remove[T any](registry *Registry, entity Entity) bool {
+	return registry.RemoveComponent(componentType[T](), entity)
+}
+
+// Each calls fn for every entity owning a component of type T.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the components.
+//	fn (func(Entity, T)): The function invoked with each entity and its component.
+func Each[T any](registry *Registry, fn func(entity Entity, component T)) {
+	NewQuery1[T](registry).Each(fn)
+}
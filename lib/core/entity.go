@@ -1,3 +1,64 @@
 package core
 
-type Entity = int
+import (
+	"fmt"
+	"sync"
+)
+
+// Entity identifies an entity within a Registry. The low 32 bits hold the index
+// of the slot the entity occupies and the high 32 bits hold the generation of that
+// slot, so handles to destroyed entities never alias entities reusing the slot.
+type Entity uint64
+
+// NilEntity is the zero Entity. It never refers to a live entity.
+const NilEntity Entity = 0
+
+// newEntity packs a slot index and generation into an Entity.
+//
+// Parameters:
+//
+//	index (uint32): The slot index of the entity.
+//	generation (uint32): The generation of the slot.
+//
+// Returns:
+//
+//	Entity: The packed entity identifier.
+func newEntity(index, generation uint32) Entity {
+	return Entity(uint64(generation)<<32 | uint64(index))
+}
+
+// Index returns the slot index of the entity.
+//
+// Returns:
+//
+//	uint32: The slot index.
+func (e Entity) Index() uint32 {
+	return uint32(e)
+}
+
+// Generation returns the generation of the entity's slot at the time the entity was created.
+//
+// Returns:
+//
+//	uint32: The generation.
+func (e Entity) Generation() uint32 {
+	return uint32(e >> 32)
+}
+
+// String returns a human readable representation of the entity.
+//
+// Returns:
+//
+//	string: The entity formatted as index and generation.
+func (e Entity) String() string {
+	return fmt.Sprintf("Entity(%dv%d)", e.Index(), e.Generation())
+}
+
+// entityAllocator hands out entity slots. Systems may reserve entities through
+// their Commands concurrently, so reservations are guarded by a mutex; a reserved
+// slot only becomes part of the registry's entity storage once it is spawned.
+type entityAllocator struct {
+	mutex       sync.Mutex
+	freeIndices []uint32
+	nextIndex   uint32
+}
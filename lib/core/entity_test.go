@@ -0,0 +1,107 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+type testPosition struct {
+	X, Y float64
+}
+
+type testVelocity struct {
+	DX, DY float64
+}
+
+// TestStaleHandleAfterRecycling checks that a handle to a destroyed entity does
+// not refer to the entity recycling its slot.
+func TestStaleHandleAfterRecycling(t *testing.T) {
+	registry := NewRegistry()
+	stale := registry.NewEntity()
+	Add(registry, stale, testPosition{X: 1})
+	if !registry.DestroyEntity(stale) {
+		t.Fatal("DestroyEntity() = false, want true")
+	}
+
+	recycled := registry.NewEntity()
+	if recycled.Index() != stale.Index() {
+		t.Fatalf("recycled index = %d, want %d", recycled.Index(), stale.Index())
+	}
+	if recycled.Generation() != stale.Generation()+1 {
+		t.Fatalf("recycled generation = %d, want %d", recycled.Generation(), stale.Generation()+1)
+	}
+	Add(registry, recycled, testPosition{X: 2})
+
+	if registry.IsAlive(stale) {
+		t.Error("IsAlive(stale) = true, want false")
+	}
+	if _, ok := Get[testPosition](registry, stale); ok {
+		t.Error("Get(stale) found a component")
+	}
+	Add(registry, stale, testVelocity{DX: 1})
+	if Has[testVelocity](registry, recycled) {
+		t.Error("Add(stale) attached a component to the recycled entity")
+	}
+	if Remove[testPosition](registry, stale) {
+		t.Error("Remove(stale) = true, want false")
+	}
+	if registry.DestroyEntity(stale) {
+		t.Error("DestroyEntity(stale) = true, want false")
+	}
+	if !registry.IsAlive(recycled) {
+		t.Fatal("recycled entity is not alive")
+	}
+	if position, _ := Get[testPosition](registry, recycled); position.X != 2 {
+		t.Errorf("recycled position = %v, want X 2", position)
+	}
+}
+
+// TestGenerationWrapSkipsZero checks that a slot whose generation overflows is
+// recycled with generation 1, so that NilEntity never becomes alive.
+func TestGenerationWrapSkipsZero(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewEntity()
+	first := registry.NewEntity()
+	registry.DestroyEntity(first)
+	registry.generations[first.Index()] = math.MaxUint32
+
+	last := registry.NewEntity()
+	if last.Generation() != math.MaxUint32 {
+		t.Fatalf("generation = %d, want %d", last.Generation(), uint32(math.MaxUint32))
+	}
+	registry.DestroyEntity(last)
+
+	wrapped := registry.NewEntity()
+	if wrapped.Index() != first.Index() {
+		t.Fatalf("wrapped index = %d, want %d", wrapped.Index(), first.Index())
+	}
+	if wrapped.Generation() != 1 {
+		t.Errorf("wrapped generation = %d, want 1", wrapped.Generation())
+	}
+	if registry.IsAlive(last) {
+		t.Error("IsAlive(last) = true, want false")
+	}
+	if registry.IsAlive(NilEntity) {
+		t.Error("IsAlive(NilEntity) = true, want false")
+	}
+}
+
+// TestAddNilComponentIsIgnored checks that adding a nil component, directly or
+// through Commands, leaves the entity unchanged instead of panicking.
+func TestAddNilComponentIsIgnored(t *testing.T) {
+	registry := NewRegistry()
+	entity := registry.NewEntity()
+	Add(registry, entity, testPosition{X: 1})
+
+	registry.AddComponent(entity, nil)
+	commands := newCommands(registry)
+	commands.AddComponent(entity, nil)
+	commands.flush()
+
+	if !registry.IsAlive(entity) {
+		t.Fatal("entity is not alive")
+	}
+	if position, ok := Get[testPosition](registry, entity); !ok || position.X != 1 {
+		t.Errorf("Get() = %v, %v, want X 1, true", position, ok)
+	}
+}
@@ -0,0 +1,116 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+)
+
+// eventQueue is the type-independent view of the events of a single type.
+type eventQueue interface {
+	update()
+}
+
+// events buffers the events of type E. Events are double-buffered: events sent
+// during a frame are moved to previous at the start of the next frame and dropped
+// at the start of the frame after that, so every reader sees every event once,
+// whether it runs before or after the sender within a frame. Frames start with
+// every call to Registry.AdvanceFrame.
+type events[E any] struct {
+	mutex         sync.RWMutex
+	previous      []E
+	current       []E
+	previousStart uint64
+	currentStart  uint64
+}
+
+// EventReader tracks which events of type E a reader has already read. Every
+// system reading events keeps its own reader. The zero value reads all events
+// that are still buffered.
+type EventReader[E any] struct {
+	cursor uint64
+}
+
+// Send broadcasts an event of type E to all readers. Send may be called from
+// several goroutines, e.g. from systems running concurrently.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry carrying the events.
+//	event (E): The event to send.
+func Send[E any](registry *Registry, event E) {
+	queue := eventsOf[E](registry)
+	queue.mutex.Lock()
+	queue.current = append(queue.current, event)
+	queue.mutex.Unlock()
+}
+
+// Read returns the events of type E sent since the reader last read them and
+// advances the reader. Events older than the previous frame are no longer
+// available.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry carrying the events.
+//	reader (*EventReader[E]): The reader whose cursor is advanced.
+//
+// Returns:
+//
+//	[]E: The unread events in the order they were sent.
+func Read[E any](registry *Registry, reader *EventReader[E]) []E {
+	queue := eventsOf[E](registry)
+	queue.mutex.RLock()
+	defer queue.mutex.RUnlock()
+
+	start := max(reader.cursor, queue.previousStart)
+	end := queue.currentStart + uint64(len(queue.current))
+	reader.cursor = end
+	if start >= end {
+		return nil
+	}
+	unread := make([]E, 0, end-start)
+	if start < queue.currentStart {
+		unread = append(unread, queue.previous[start-queue.previousStart:]...)
+		start = queue.currentStart
+	}
+	return append(unread, queue.current[start-queue.currentStart:]...)
+}
+
+// eventsOf returns the event buffers of type E, creating them if needed.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry carrying the events.
+//
+// Returns:
+//
+//	*events[E]: The event buffers.
+func eventsOf[E any](registry *Registry) *events[E] {
+	identifier := reflect.TypeOf((*E)(nil)).Elem()
+	registry.eventsMutex.Lock()
+	defer registry.eventsMutex.Unlock()
+	queue, ok := registry.events[identifier].(*events[E])
+	if !ok {
+		queue = &events[E]{}
+		registry.events[identifier] = queue
+	}
+	return queue
+}
+
+// update drops the events of the previous frame and keeps the events of the
+// current frame for one more frame.
+func (e *events[E]) update() {
+	e.mutex.Lock()
+	e.previous, e.previousStart = e.current, e.currentStart
+	e.currentStart += uint64(len(e.current))
+	e.current = nil
+	e.mutex.Unlock()
+}
+
+// updateEvents swaps the event buffers of every event type.
+func (r *Registry) updateEvents() {
+	r.eventsMutex.Lock()
+	defer r.eventsMutex.Unlock()
+	for _, queue := range r.events {
+		queue.update()
+	}
+}
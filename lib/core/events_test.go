@@ -0,0 +1,90 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testEvent is an event carrying a sequence number.
+type testEvent struct {
+	N int
+}
+
+// TestEventReadersKeepTheirOwnCursor checks that every reader sees every event
+// once, regardless of when the other readers read.
+func TestEventReadersKeepTheirOwnCursor(t *testing.T) {
+	registry := NewRegistry()
+	var early, late EventReader[testEvent]
+
+	Send(registry, testEvent{1})
+	if got := Read(registry, &early); !reflect.DeepEqual(got, []testEvent{{1}}) {
+		t.Fatalf("early Read() = %v, want [{1}]", got)
+	}
+	Send(registry, testEvent{2})
+	if got := Read(registry, &early); !reflect.DeepEqual(got, []testEvent{{2}}) {
+		t.Fatalf("early Read() = %v, want [{2}]", got)
+	}
+	if got := Read(registry, &late); !reflect.DeepEqual(got, []testEvent{{1}, {2}}) {
+		t.Fatalf("late Read() = %v, want [{1} {2}]", got)
+	}
+	if got := Read(registry, &early); got != nil {
+		t.Errorf("repeated Read() = %v, want none", got)
+	}
+}
+
+// TestEventsLiveForTwoFrames checks that events stay readable during the frame
+// after the one they were sent in and are dropped afterwards.
+func TestEventsLiveForTwoFrames(t *testing.T) {
+	registry := NewRegistry()
+	var before, after, missed EventReader[testEvent]
+
+	Send(registry, testEvent{1})
+	registry.AdvanceFrame()
+	Send(registry, testEvent{2})
+	if got := Read(registry, &before); !reflect.DeepEqual(got, []testEvent{{1}, {2}}) {
+		t.Fatalf("Read() in the next frame = %v, want [{1} {2}]", got)
+	}
+
+	registry.AdvanceFrame()
+	Send(registry, testEvent{3})
+	if got := Read(registry, &before); !reflect.DeepEqual(got, []testEvent{{3}}) {
+		t.Errorf("Read() after reading = %v, want [{3}]", got)
+	}
+	if got := Read(registry, &after); !reflect.DeepEqual(got, []testEvent{{2}, {3}}) {
+		t.Errorf("Read() two frames later = %v, want [{2} {3}]", got)
+	}
+
+	registry.AdvanceFrame()
+	registry.AdvanceFrame()
+	if got := Read(registry, &missed); got != nil {
+		t.Errorf("Read() after all events expired = %v, want none", got)
+	}
+	if queue := eventsOf[testEvent](registry); len(queue.previous)+len(queue.current) != 0 {
+		t.Errorf("buffered %d events, want none", len(queue.previous)+len(queue.current))
+	}
+}
+
+// TestEventsAcrossSystems checks that a system reading before the sender in the
+// schedule receives the events on its next run.
+func TestEventsAcrossSystems(t *testing.T) {
+	registry := NewRegistry()
+	var received [][]testEvent
+	reader := EventReader[testEvent]{}
+	registry.AddSystem("reader", funcSystem(func(registry *Registry, _ *Commands) {
+		received = append(received, Read(registry, &reader))
+	}))
+	sent := 0
+	registry.AddSystem("sender", funcSystem(func(registry *Registry, _ *Commands) {
+		sent++
+		Send(registry, testEvent{sent})
+	}), After("reader"))
+
+	runFrame(t, registry)
+	runFrame(t, registry)
+	runFrame(t, registry)
+
+	want := [][]testEvent{nil, {{1}}, {{2}}}
+	if !reflect.DeepEqual(received, want) {
+		t.Errorf("received %v, want %v", received, want)
+	}
+}
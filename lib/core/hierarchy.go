@@ -0,0 +1,152 @@
+package core
+
+import "fmt"
+
+// Parent is attached to an entity that is the child of another entity. It is
+// maintained by SetParent and RemoveParent and must not be added directly.
+type Parent struct {
+	Entity Entity
+}
+
+// MapEntities returns the component referring to the remapped parent.
+//
+// Parameters:
+//
+//	mapEntity (func(Entity) Entity): Maps an entity to its new identifier.
+//
+// Returns:
+//
+//	Component: The remapped component.
+func (p Parent) MapEntities(mapEntity func(entity Entity) Entity) Component {
+	return Parent{Entity: mapEntity(p.Entity)}
+}
+
+// Children is attached to an entity that has child entities, listing them in the
+// order they were attached. It is maintained by SetParent and RemoveParent and
+// must not be added directly.
+type Children struct {
+	Entities []Entity
+}
+
+// MapEntities returns the component referring to the remapped children.
+//
+// Parameters:
+//
+//	mapEntity (func(Entity) Entity): Maps an entity to its new identifier.
+//
+// Returns:
+//
+//	Component: The remapped component.
+func (c Children) MapEntities(mapEntity func(entity Entity) Entity) Component {
+	entities := make([]Entity, len(c.Entities))
+	for idx, entity := range c.Entities {
+		entities[idx] = mapEntity(entity)
+	}
+	return Children{Entities: entities}
+}
+
+// SetParent attaches an entity as the child of another entity, detaching it from
+// its previous parent. Destroying a parent destroys all of its descendants.
+//
+// Parameters:
+//
+//	child (Entity): The entity to attach.
+//	parent (Entity): The entity to attach it to.
+//
+// Returns:
+//
+//	error: An error if either entity is not alive or the parent is a descendant of the child.
+func (r *Registry) SetParent(child, parent Entity) error {
+	if !r.IsAlive(child) {
+		return fmt.Errorf("core: cannot attach %v, it is not alive", child)
+	}
+	if !r.IsAlive(parent) {
+		return fmt.Errorf("core: cannot attach %v to %v, it is not alive", child, parent)
+	}
+	for ancestor := parent; ancestor != NilEntity; ancestor = r.parentOf(ancestor) {
+		if ancestor == child {
+			return fmt.Errorf("core: cannot attach %v to its descendant %v", child, parent)
+		}
+	}
+
+	if previous := r.parentOf(child); previous == parent {
+		return nil
+	} else if previous != NilEntity {
+		r.unlinkChild(previous, child)
+	}
+	children, _ := Get[Children](r, parent)
+	Add(r, parent, Children{Entities: append(children.Entities[:len(children.Entities):len(children.Entities)], child)})
+	Add(r, child, Parent{Entity: parent})
+	return nil
+}
+
+// RemoveParent detaches an entity from its parent, making it a root entity.
+//
+// Parameters:
+//
+//	child (Entity): The entity to detach.
+//
+// Returns:
+//
+//	bool: True if the entity had a parent.
+func (r *Registry) RemoveParent(child Entity) bool {
+	parent := r.parentOf(child)
+	if parent == NilEntity {
+		return false
+	}
+	r.unlinkChild(parent, child)
+	Remove[Parent](r, child)
+	return true
+}
+
+// parentOf returns the parent of an entity.
+//
+// Parameters:
+//
+//	entity (Entity): The entity whose parent is returned.
+//
+// Returns:
+//
+//	Entity: The parent, or NilEntity if the entity is a root entity.
+func (r *Registry) parentOf(entity Entity) Entity {
+	parent, _ := Get[Parent](r, entity)
+	return parent.Entity
+}
+
+// unlinkChild removes an entity from the children of its parent, removing the
+// Children component once the last child is gone.
+//
+// Parameters:
+//
+//	parent (Entity): The parent entity.
+//	child (Entity): The child to remove.
+func (r *Registry) unlinkChild(parent, child Entity) {
+	children, ok := Get[Children](r, parent)
+	if !ok {
+		return
+	}
+	remaining := without(children.Entities, child)
+	if len(remaining) == 0 {
+		Remove[Children](r, parent)
+		return
+	}
+	Add(r, parent, Children{Entities: remaining})
+}
+
+// destroyHierarchy destroys the descendants of an entity that is about to be
+// destroyed and detaches it from its parent.
+//
+// Parameters:
+//
+//	entity (Entity): The entity about to be destroyed.
+//	hooks (bool): Whether the remove hooks of the descendants run.
+func (r *Registry) destroyHierarchy(entity Entity, hooks bool) {
+	if children, ok := Get[Children](r, entity); ok {
+		for _, child := range children.Entities {
+			r.destroyEntity(child, hooks)
+		}
+	}
+	if parent := r.parentOf(entity); parent != NilEntity {
+		r.unlinkChild(parent, entity)
+	}
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// newHierarchy creates a root with two children, the first of which has a child
+// of its own.
+func newHierarchy(registry *Registry) (root, first, second, grandchild Entity) {
+	root, first, second, grandchild = registry.NewEntity(), registry.NewEntity(), registry.NewEntity(), registry.NewEntity()
+	registry.SetParent(first, root)
+	registry.SetParent(second, root)
+	registry.SetParent(grandchild, first)
+	return root, first, second, grandchild
+}
+
+// childrenOf returns the children of an entity, or nil if it has none.
+func childrenOf(registry *Registry, entity Entity) []Entity {
+	children, _ := Get[Children](registry, entity)
+	return children.Entities
+}
+
+// TestSetParentRejectsCycles checks that an entity cannot be attached to itself
+// or to one of its descendants, and that a rejected call changes nothing.
+func TestSetParentRejectsCycles(t *testing.T) {
+	registry := NewRegistry()
+	root, first, _, grandchild := newHierarchy(registry)
+
+	for _, parent := range []Entity{root, first, grandchild} {
+		if err := registry.SetParent(root, parent); err == nil {
+			t.Errorf("SetParent(root, %v) error = nil, want a cycle error", parent)
+		}
+	}
+	if registry.parentOf(root) != NilEntity {
+		t.Errorf("root parent = %v, want none", registry.parentOf(root))
+	}
+	if got := childrenOf(registry, grandchild); got != nil {
+		t.Errorf("grandchild children = %v, want none", got)
+	}
+
+	dead := registry.NewEntity()
+	registry.DestroyEntity(dead)
+	if err := registry.SetParent(first, dead); err == nil {
+		t.Error("SetParent() to a destroyed parent error = nil, want an error")
+	}
+}
+
+// TestSetParentMovesChild checks that reattaching an entity detaches it from its
+// previous parent.
+func TestSetParentMovesChild(t *testing.T) {
+	registry := NewRegistry()
+	root, first, second, grandchild := newHierarchy(registry)
+
+	if err := registry.SetParent(grandchild, second); err != nil {
+		t.Fatalf("SetParent() error = %v", err)
+	}
+	if Has[Children](registry, first) {
+		t.Errorf("first children = %v, want the component removed", childrenOf(registry, first))
+	}
+	if got, want := childrenOf(registry, second), []Entity{grandchild}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second children = %v, want %v", got, want)
+	}
+	if got, want := childrenOf(registry, root), []Entity{first, second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("root children = %v, want %v", got, want)
+	}
+}
+
+// TestRemoveParent checks that a detached entity becomes a root and keeps its own
+// children.
+func TestRemoveParent(t *testing.T) {
+	registry := NewRegistry()
+	root, first, second, grandchild := newHierarchy(registry)
+
+	if !registry.RemoveParent(first) {
+		t.Fatal("RemoveParent() = false, want true")
+	}
+	if registry.RemoveParent(first) {
+		t.Error("RemoveParent() twice = true, want false")
+	}
+	if Has[Parent](registry, first) {
+		t.Error("detached entity still has a Parent")
+	}
+	if got, want := childrenOf(registry, root), []Entity{second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("root children = %v, want %v", got, want)
+	}
+	if got, want := childrenOf(registry, first), []Entity{grandchild}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first children = %v, want %v", got, want)
+	}
+}
+
+// TestDestroyParentDestroysDescendants checks that destroying an entity destroys
+// its descendants and removes it from the children of its parent.
+func TestDestroyParentDestroysDescendants(t *testing.T) {
+	registry := NewRegistry()
+	root, first, second, grandchild := newHierarchy(registry)
+
+	registry.DestroyEntity(first)
+	for _, entity := range []Entity{first, grandchild} {
+		if registry.IsAlive(entity) {
+			t.Errorf("IsAlive(%v) = true, want false", entity)
+		}
+	}
+	if !registry.IsAlive(root) || !registry.IsAlive(second) {
+		t.Fatal("destroying a child destroyed its parent or sibling")
+	}
+	if got, want := childrenOf(registry, root), []Entity{second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("root children = %v, want %v", got, want)
+	}
+
+	registry.DestroyEntity(root)
+	if registry.IsAlive(second) {
+		t.Error("destroying the root kept its child alive")
+	}
+}
@@ -0,0 +1,146 @@
+package core
+
+import "reflect"
+
+// componentHooks holds the lifecycle hooks registered for a component type. The
+// hooks receive components boxed as Component and convert them back to the
+// registered type.
+type componentHooks struct {
+	onAdd     []func(registry *Registry, entity Entity, component Component)
+	onReplace []func(registry *Registry, entity Entity, previous, component Component)
+	onRemove  []func(registry *Registry, entity Entity, component Component)
+}
+
+// OnAdd registers a hook that is invoked synchronously whenever a component of
+// type T is added to an entity that did not own one before. The component is
+// already stored when the hook runs.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to register the hook with.
+//	hook (func(*Registry, Entity, T)): Invoked with the entity and the added component.
+func OnAdd[T any](registry *Registry, hook func(registry *Registry, entity Entity, component T)) {
+	hooks := registry.hooksOf(componentType[T]())
+	hooks.onAdd = append(hooks.onAdd, func(registry *Registry, entity Entity, component Component) {
+		hook(registry, entity, component.(T))
+	})
+}
+
+// OnReplace registers a hook that is invoked synchronously whenever a component of
+// type T replaces an existing one. The new component is already stored when the
+// hook runs.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to register the hook with.
+//	hook (func(*Registry, Entity, T, T)): Invoked with the entity, the replaced and the new component.
+func OnReplace[T any](registry *Registry, hook func(registry *Registry, entity Entity, previous, component T)) {
+	hooks := registry.hooksOf(componentType[T]())
+	hooks.onReplace = append(hooks.onReplace, func(registry *Registry, entity Entity, previous, component Component) {
+		hook(registry, entity, previous.(T), component.(T))
+	})
+}
+
+// OnRemove registers a hook that is invoked synchronously whenever a component of
+// type T is removed from an entity, including when the entity is destroyed. The
+// entity still owns the component when the hook runs.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to register the hook with.
+//	hook (func(*Registry, Entity, T)): Invoked with the entity and the removed component.
+func OnRemove[T any](registry *Registry, hook func(registry *Registry, entity Entity, component T)) {
+	hooks := registry.hooksOf(componentType[T]())
+	hooks.onRemove = append(hooks.onRemove, func(registry *Registry, entity Entity, component Component) {
+		hook(registry, entity, component.(T))
+	})
+}
+
+// hooksOf returns the hooks of a component type, creating them if needed.
+//
+// Parameters:
+//
+//	identifier (reflect.Type): The component type.
+//
+// Returns:
+//
+//	*componentHooks: The hooks of the component type.
+func (r *Registry) hooksOf(identifier reflect.Type) *componentHooks {
+	hooks := r.hooks[identifier]
+	if hooks == nil {
+		hooks = &componentHooks{}
+		r.hooks[identifier] = hooks
+	}
+	return hooks
+}
+
+// added invokes the add hooks.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	entity (Entity): The entity the component was added to.
+//	component (Component): The added component.
+func (h *componentHooks) added(registry *Registry, entity Entity, component Component) {
+	for _, hook := range h.onAdd {
+		hook(registry, entity, component)
+	}
+}
+
+// replaced invokes the replace hooks.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	entity (Entity): The entity whose component was replaced.
+//	previous (Component): The replaced component.
+//	component (Component): The new component.
+func (h *componentHooks) replaced(registry *Registry, entity Entity, previous, component Component) {
+	for _, hook := range h.onReplace {
+		hook(registry, entity, previous, component)
+	}
+}
+
+// removed invokes the remove hooks.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	entity (Entity): The entity the component is removed from.
+//	component (Component): The removed component.
+func (h *componentHooks) removed(registry *Registry, entity Entity, component Component) {
+	for _, hook := range h.onRemove {
+		hook(registry, entity, component)
+	}
+}
+
+// runRemoveHooks invokes the remove hooks of every component an entity owns,
+// before the entity is destroyed.
+//
+// Parameters:
+//
+//	entity (Entity): The entity about to be destroyed.
+func (r *Registry) runRemoveHooks(entity Entity) {
+	type pending struct {
+		hooks     *componentHooks
+		component Component
+	}
+	var removals []pending
+	record := r.records[entity.Index()]
+	for idx, info := range record.archetype.components {
+		if len(info.hooks.onRemove) > 0 {
+			removals = append(removals, pending{info.hooks, record.archetype.columns[idx].get(record.row)})
+		}
+	}
+	for _, info := range r.sparseInfos {
+		if len(info.hooks.onRemove) == 0 {
+			continue
+		}
+		if row, ok := info.sparse.row(entity); ok {
+			removals = append(removals, pending{info.hooks, info.sparse.data.get(row)})
+		}
+	}
+	for _, removal := range removals {
+		removal.hooks.removed(r, entity, removal.component)
+	}
+}
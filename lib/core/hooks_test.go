@@ -0,0 +1,119 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// TestHookOrder checks that hooks run in registration order, see the stored
+// component, and that remove hooks run while the entity still owns it.
+func TestHookOrder(t *testing.T) {
+	registry := NewRegistry()
+	var log []string
+	OnAdd(registry, func(registry *Registry, entity Entity, position testPosition) {
+		stored, _ := Get[testPosition](registry, entity)
+		log = append(log, "add 1", "stored "+formatX(stored))
+	})
+	OnAdd(registry, func(*Registry, Entity, testPosition) {
+		log = append(log, "add 2")
+	})
+	OnReplace(registry, func(registry *Registry, entity Entity, previous, position testPosition) {
+		stored, _ := Get[testPosition](registry, entity)
+		log = append(log, "replace "+formatX(previous)+" with "+formatX(position), "stored "+formatX(stored))
+	})
+	OnRemove(registry, func(registry *Registry, entity Entity, position testPosition) {
+		if !registry.IsAlive(entity) || !Has[testPosition](registry, entity) {
+			t.Error("remove hook ran after the component was removed")
+		}
+		log = append(log, "remove "+formatX(position))
+	})
+
+	entity := registry.NewEntity()
+	Add(registry, entity, testPosition{X: 1})
+	Add(registry, entity, testPosition{X: 2})
+	Remove[testPosition](registry, entity)
+	Add(registry, entity, testPosition{X: 3})
+	registry.DestroyEntity(entity)
+
+	want := []string{
+		"add 1", "stored 1", "add 2",
+		"replace 1 with 2", "stored 2",
+		"remove 2",
+		"add 1", "stored 3", "add 2",
+		"remove 3",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("hooks ran as %q, want %q", log, want)
+	}
+}
+
+// TestHookReentrantDestroy checks that destroying an entity or removing its
+// components from within its own remove hooks has no effect on the hooks, which
+// run once per component.
+func TestHookReentrantDestroy(t *testing.T) {
+	registry := NewRegistry()
+	calls := 0
+	OnRemove(registry, func(registry *Registry, entity Entity, _ testPosition) {
+		calls++
+		if registry.DestroyEntity(entity) {
+			t.Error("DestroyEntity() from a remove hook = true, want false")
+		}
+	})
+	OnRemove(registry, func(registry *Registry, entity Entity, _ testVelocity) {
+		calls++
+		registry.RemoveComponent(componentType[testPosition](), entity)
+	})
+
+	entity := registry.NewEntity()
+	Add(registry, entity, testPosition{})
+	Add(registry, entity, testVelocity{})
+	if !registry.DestroyEntity(entity) {
+		t.Fatal("DestroyEntity() = false, want true")
+	}
+	if registry.IsAlive(entity) {
+		t.Error("entity is alive after DestroyEntity()")
+	}
+	if calls != 2 {
+		t.Errorf("remove hooks ran %d times, want 2", calls)
+	}
+}
+
+// TestHooksRegisteredAfterFirstUse checks that hooks registered after a type has
+// been stored, in table or sparse-set storage, apply to existing components.
+func TestHooksRegisteredAfterFirstUse(t *testing.T) {
+	registry := NewRegistry()
+	if err := RegisterComponent[testVelocity](registry, StorageSparseSet); err != nil {
+		t.Fatalf("RegisterComponent() error = %v", err)
+	}
+	entity := registry.NewEntity()
+	Add(registry, entity, testPosition{X: 1})
+	Add(registry, entity, testVelocity{DX: 1})
+
+	var removed []string
+	OnRemove(registry, func(_ *Registry, _ Entity, position testPosition) {
+		removed = append(removed, "position "+formatX(position))
+	})
+	OnRemove(registry, func(_ *Registry, _ Entity, velocity testVelocity) {
+		removed = append(removed, "velocity")
+	})
+	var replaced int
+	OnReplace(registry, func(_ *Registry, _ Entity, previous, velocity testVelocity) {
+		replaced++
+	})
+
+	Add(registry, entity, testVelocity{DX: 2})
+	registry.DestroyEntity(entity)
+
+	if replaced != 1 {
+		t.Errorf("replace hook ran %d times, want 1", replaced)
+	}
+	if want := []string{"position 1", "velocity"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("remove hooks ran as %q, want %q", removed, want)
+	}
+}
+
+// formatX formats the X coordinate of a position for hook logs.
+func formatX(position testPosition) string {
+	return fmt.Sprint(position.X)
+}
@@ -0,0 +1,89 @@
+package core
+
+import (
+	"runtime"
+	"sync"
+)
+
+// DefaultBatchSize is the number of entities per batch used by ParallelForEach
+// when no positive batch size is given.
+const DefaultBatchSize = 256
+
+// SetWorkerCount sets the number of goroutines ParallelForEach spreads batches
+// across. The default is runtime.GOMAXPROCS(0).
+//
+// Parameters:
+//
+//	workers (int): The number of workers; values below 1 restore the default.
+func (r *Registry) SetWorkerCount(workers int) {
+	r.workers = workers
+}
+
+// workerCount returns the number of goroutines used by ParallelForEach.
+//
+// Returns:
+//
+//	int: The number of workers.
+func (r *Registry) workerCount() int {
+	if r.workers < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return r.workers
+}
+
+// parallelMatch splits the entities matched by a query into tasks of about
+// batchSize entities and visits the tasks concurrently on the worker pool.
+//
+// Parameters:
+//
+//	infos ([]*componentInfo): The requested component types.
+//	filters ([]QueryFilter): The filters to apply.
+//	batchSize (int): The number of entities per task; DefaultBatchSize if not positive.
+//	visit (func(queryBatch)): Invoked with each batch of matching entities.
+func (r *Registry) parallelMatch(infos []*componentInfo, filters []QueryFilter, batchSize int, visit func(batch queryBatch)) {
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+
+	var tasks [][]queryBatch
+	var current []queryBatch
+	size := 0
+	r.eachMatch(infos, filters, func(batch queryBatch) {
+		for start := 0; start < len(batch.entities); {
+			end := min(len(batch.entities), start+batchSize-size)
+			chunk := queryBatch{arch: batch.arch, entities: batch.entities[start:end], offset: batch.offset + start}
+			if batch.rows != nil {
+				chunk.rows = batch.rows[start:end]
+			}
+			current = append(current, chunk)
+			size += end - start
+			start = end
+			if size == batchSize {
+				tasks = append(tasks, current)
+				current, size = nil, 0
+			}
+		}
+	})
+	if len(current) > 0 {
+		tasks = append(tasks, current)
+	}
+
+	queue := make(chan []queryBatch)
+	var group sync.WaitGroup
+	for worker := 0; worker < min(r.workerCount(), len(tasks)); worker++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for task := range queue {
+				for _, batch := range task {
+					visit(batch)
+				}
+			}
+		}()
+	}
+	for _, task := range tasks {
+		queue <- task
+	}
+	close(queue)
+	group.Wait()
+}
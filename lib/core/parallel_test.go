@@ -0,0 +1,121 @@
+package core
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestParallelMatchSplitsBatches checks that batches are cut at batchSize across
+// archetype boundaries and that the last batch holds the remainder.
+func TestParallelMatchSplitsBatches(t *testing.T) {
+	registry := NewRegistry()
+	for i := 0; i < 10; i++ {
+		Add(registry, registry.NewEntity(), testPosition{})
+	}
+	for i := 0; i < 7; i++ {
+		entity := registry.NewEntity()
+		Add(registry, entity, testPosition{})
+		Add(registry, entity, testTag{})
+	}
+	registry.SetWorkerCount(1)
+
+	var sizes []int
+	infos := registry.queryInfos(componentType[testPosition]())
+	registry.parallelMatch(infos, nil, 4, func(batch queryBatch) {
+		sizes = append(sizes, len(batch.entities))
+	})
+	if want := []int{4, 4, 2, 2, 4, 1}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("batch sizes = %v, want %v", sizes, want)
+	}
+}
+
+// TestParallelForEachVisitsEveryEntityOnce checks batch sizes that do not divide
+// the number of entities and several worker counts.
+func TestParallelForEachVisitsEveryEntityOnce(t *testing.T) {
+	const entities = 1000
+	registry := newMixedRegistry(t, entities)
+	for _, workers := range []int{1, 3, 0} {
+		for _, batchSize := range []int{0, 1, 7, 333, entities, 2 * entities} {
+			registry.SetWorkerCount(workers)
+			var visits [entities]atomic.Int32
+			NewQuery2[*testPosition, *testVelocity](registry).ParallelForEach(batchSize, func(entity Entity, _ *testPosition, _ *testVelocity) {
+				visits[entity.Index()].Add(1)
+			})
+			for index := range visits {
+				if count := visits[index].Load(); count != 1 {
+					t.Fatalf("workers %d, batch size %d: entity %d visited %d times, want 1", workers, batchSize, index, count)
+				}
+			}
+		}
+	}
+}
+
+// TestSetWorkerCountBoundsConcurrency checks that no more batches than workers are
+// visited at the same time and that a count below 1 restores the default.
+func TestSetWorkerCountBoundsConcurrency(t *testing.T) {
+	registry := newMixedRegistry(t, 64)
+	registry.SetWorkerCount(2)
+
+	var active, peak atomic.Int32
+	NewQuery1[*testPosition](registry).ParallelForEach(1, func(Entity, *testPosition) {
+		current := active.Add(1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(100 * time.Microsecond)
+		active.Add(-1)
+	})
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", got)
+	}
+
+	registry.SetWorkerCount(0)
+	if got, want := registry.workerCount(), runtime.GOMAXPROCS(0); got != want {
+		t.Errorf("workerCount() = %d, want %d", got, want)
+	}
+}
+
+// TestParallelForEachMarkChanged checks that MarkChanged may be called for the
+// visited entities of every storage from within ParallelForEach.
+func TestParallelForEachMarkChanged(t *testing.T) {
+	registry := newMixedRegistry(t, 1000)
+	registry.SetWorkerCount(4)
+
+	var mutex sync.Mutex
+	marked := make(map[Entity]bool)
+	registry.AddSystem("move", funcSystem(func(registry *Registry, commands *Commands) {
+		NewQuery2[*testPosition, *testVelocity](registry).ParallelForEach(16, func(entity Entity, position *testPosition, velocity *testVelocity) {
+			if entity.Index()%3 != 0 {
+				return
+			}
+			position.X += velocity.DX
+			MarkChanged[*testPosition](registry, entity)
+			MarkChanged[*testVelocity](registry, entity)
+			mutex.Lock()
+			marked[entity] = true
+			mutex.Unlock()
+		})
+	}))
+	since := registry.ChangeTick()
+	runFrame(t, registry)
+
+	for _, filter := range []QueryFilter{Changed[*testPosition](since), Changed[*testVelocity](since)} {
+		changed := 0
+		NewQuery1[*testPosition](registry, filter).Each(func(entity Entity, _ *testPosition) {
+			if !marked[entity] {
+				t.Errorf("%v changed but was not marked", entity)
+			}
+			changed++
+		})
+		if changed != len(marked) {
+			t.Errorf("%d entities changed, want %d", changed, len(marked))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package core
+
+// Phase identifies a stage of the game loop that systems are registered into.
+// Phases run in the order they are declared.
+type Phase int
+
+const (
+	// PhasePreUpdate runs first in every tick, e.g. to gather input.
+	PhasePreUpdate Phase = iota
+	// PhaseFixedUpdate runs once per fixed-rate simulation tick, e.g. for movement.
+	PhaseFixedUpdate
+	// PhaseUpdate runs the general game logic.
+	PhaseUpdate
+	// PhasePostUpdate runs after the game logic, e.g. to react to its results.
+	PhasePostUpdate
+	// PhaseRender draws the world. Only Renderers are registered into it.
+	PhaseRender
+)
+
+// UpdatePhases lists the phases run by a game loop's update step, in order.
+var UpdatePhases = []Phase{
+	PhasePreUpdate,
+	PhaseFixedUpdate,
+	PhaseUpdate,
+	PhasePostUpdate,
+}
+
+// String returns the name of the phase.
+//
+// Returns:
+//
+//	string: The name of the phase.
+func (p Phase) String() string {
+	switch p {
+	case PhasePreUpdate:
+		return "PreUpdate"
+	case PhaseFixedUpdate:
+		return "FixedUpdate"
+	case PhaseUpdate:
+		return "Update"
+	case PhasePostUpdate:
+		return "PostUpdate"
+	case PhaseRender:
+		return "Render"
+	default:
+		return "Phase(unknown)"
+	}
+}
+
+// InPhase registers a system into the given phase instead of PhaseUpdate.
+//
+// Parameters:
+//
+//	phase (Phase): The phase the system runs in.
+//
+// Returns:
+//
+//	SystemOption: The option assigning the phase.
+func InPhase(phase Phase) SystemOption {
+	return func(entry *systemEntry) {
+		entry.phase = phase
+	}
+}
@@ -0,0 +1,214 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Prefab describes the components of an entity in JSON, keyed by the names of
+// their serializable types. A prefab may extend another prefab: it inherits all
+// of its components, overrides fields by merging JSON objects, and drops an
+// inherited component by setting it to null.
+//
+//	{
+//		"extends": "character",
+//		"components": {
+//			"transform": {"Speed": 2},
+//			"player": {}
+//		}
+//	}
+type Prefab struct {
+	Extends    string                     `json:"extends,omitempty"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+// PrefabLibrary holds named prefabs and spawns entities from them.
+type PrefabLibrary struct {
+	types   *SerializableTypes
+	prefabs map[string]*Prefab
+}
+
+// NewPrefabLibrary creates an empty prefab library.
+//
+// Parameters:
+//
+//	types (*SerializableTypes): The component types prefabs may contain.
+//
+// Returns:
+//
+//	*PrefabLibrary: The newly created library.
+func NewPrefabLibrary(types *SerializableTypes) *PrefabLibrary {
+	return &PrefabLibrary{
+		types:   types,
+		prefabs: make(map[string]*Prefab),
+	}
+}
+
+// Add parses a prefab and stores it under a name, replacing any prefab of the
+// same name.
+//
+// Parameters:
+//
+//	name (string): The name of the prefab.
+//	data ([]byte): The JSON description of the prefab.
+//
+// Returns:
+//
+//	error: An error if the description is not valid JSON.
+func (l *PrefabLibrary) Add(name string, data []byte) error {
+	prefab := &Prefab{}
+	if err := json.Unmarshal(data, prefab); err != nil {
+		return fmt.Errorf("core: parsing prefab %q: %w", name, err)
+	}
+	l.prefabs[name] = prefab
+	return nil
+}
+
+// LoadFS adds every file of a file system matching a pattern as a prefab. The
+// name of a prefab is its file name up to the first dot, so that
+// prefabs/goblin.prefab.json is named goblin.
+//
+// Parameters:
+//
+//	fsys (fs.FS): The file system to read, e.g. an embed.FS.
+//	pattern (string): The pattern of the files, as accepted by fs.Glob.
+//
+// Returns:
+//
+//	error: An error if a file cannot be read or parsed.
+func (l *PrefabLibrary) LoadFS(fsys fs.FS, pattern string) error {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		name, _, _ := strings.Cut(path.Base(file), ".")
+		if err := l.Add(name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Spawn creates an entity with the components of a prefab, including those it
+// inherits. Nothing is spawned if a component cannot be decoded.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to spawn the entity into.
+//	name (string): The name of the prefab.
+//
+// Returns:
+//
+//	Entity: The identifier of the spawned entity.
+//	error: An error if the prefab or a prefab it extends is unknown, the prefabs extend each other in a cycle, or a component cannot be decoded.
+func (l *PrefabLibrary) Spawn(registry *Registry, name string) (Entity, error) {
+	fields, err := l.resolve(name, nil)
+	if err != nil {
+		return NilEntity, err
+	}
+
+	names := make([]string, 0, len(fields))
+	for typeName := range fields {
+		names = append(names, typeName)
+	}
+	slices.Sort(names)
+
+	values := make([]savedValue, 0, len(names))
+	for _, typeName := range names {
+		entry, err := l.types.lookup(typeName)
+		if err != nil {
+			return NilEntity, fmt.Errorf("core: prefab %q: %w", name, err)
+		}
+		value := reflect.New(entry.identifier)
+		if err := json.Unmarshal(fields[typeName], value.Interface()); err != nil {
+			return NilEntity, fmt.Errorf("core: prefab %q: decoding %q: %w", name, typeName, err)
+		}
+		values = append(values, savedValue{entry: entry, value: value.Elem().Interface()})
+	}
+
+	entity := registry.NewEntity()
+	for _, value := range values {
+		info := registry.registerComponentStorage(value.entry.identifier, value.entry.newColumn, StorageTable)
+		registry.addComponent(info, entity, value.value)
+	}
+	return entity, nil
+}
+
+// resolve merges the components of a prefab with those it inherits.
+//
+// Parameters:
+//
+//	name (string): The name of the prefab.
+//	visiting ([]string): The prefabs extending this one, to detect cycles.
+//
+// Returns:
+//
+//	map[string]json.RawMessage: The merged components keyed by type name.
+//	error: An error if a prefab is unknown or the prefabs extend each other in a cycle.
+func (l *PrefabLibrary) resolve(name string, visiting []string) (map[string]json.RawMessage, error) {
+	if slices.Contains(visiting, name) {
+		return nil, fmt.Errorf("core: prefabs extend each other in a cycle: %s", strings.Join(append(visiting, name), " -> "))
+	}
+	prefab, ok := l.prefabs[name]
+	if !ok {
+		return nil, fmt.Errorf("core: unknown prefab %q", name)
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if prefab.Extends != "" {
+		inherited, err := l.resolve(prefab.Extends, append(visiting, name))
+		if err != nil {
+			return nil, err
+		}
+		fields = inherited
+	}
+	for typeName, override := range prefab.Components {
+		if string(override) == "null" {
+			delete(fields, typeName)
+			continue
+		}
+		merged, err := mergeJSON(fields[typeName], override)
+		if err != nil {
+			return nil, fmt.Errorf("core: prefab %q: merging %q: %w", name, typeName, err)
+		}
+		fields[typeName] = merged
+	}
+	return fields, nil
+}
+
+// mergeJSON overrides the fields of a JSON value. Objects are merged key by key,
+// recursively; any other value is replaced.
+//
+// Parameters:
+//
+//	base (json.RawMessage): The inherited value, or nil.
+//	override (json.RawMessage): The overriding value.
+//
+// Returns:
+//
+//	json.RawMessage: The merged value.
+//	error: An error if a value is not valid JSON.
+func mergeJSON(base, override json.RawMessage) (json.RawMessage, error) {
+	var baseFields, overrideFields map[string]json.RawMessage
+	if json.Unmarshal(base, &baseFields) != nil || baseFields == nil || json.Unmarshal(override, &overrideFields) != nil || overrideFields == nil {
+		return override, nil
+	}
+	for key, value := range overrideFields {
+		merged, err := mergeJSON(baseFields[key], value)
+		if err != nil {
+			return nil, err
+		}
+		baseFields[key] = merged
+	}
+	return json.Marshal(baseFields)
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// newTestPrefabs creates a library of prefabs extending each other.
+func newTestPrefabs(t *testing.T) *PrefabLibrary {
+	t.Helper()
+	library := NewPrefabLibrary(newTestTypes(t))
+	err := library.LoadFS(fstest.MapFS{
+		"prefabs/base.prefab.json":  {Data: []byte(`{"components": {"position": {"X": 1, "Y": 2}, "tag": {}}}`)},
+		"prefabs/moved.prefab.json": {Data: []byte(`{"extends": "base", "components": {"position": {"Y": 5}}}`)},
+		"prefabs/plain.prefab.json": {Data: []byte(`{"extends": "moved", "components": {"tag": null}}`)},
+		"prefabs/readme.txt":        {Data: []byte(`not a prefab`)},
+	}, "prefabs/*.prefab.json")
+	if err != nil {
+		t.Fatalf("LoadFS() error = %v", err)
+	}
+	return library
+}
+
+// TestPrefabExtends checks that prefabs inherit components, merge overridden
+// fields and drop components set to null.
+func TestPrefabExtends(t *testing.T) {
+	library := newTestPrefabs(t)
+	registry := NewRegistry()
+	tests := []struct {
+		name     string
+		position testPosition
+		tagged   bool
+	}{
+		{"base", testPosition{X: 1, Y: 2}, true},
+		{"moved", testPosition{X: 1, Y: 5}, true},
+		{"plain", testPosition{X: 1, Y: 5}, false},
+	}
+	for _, test := range tests {
+		entity, err := library.Spawn(registry, test.name)
+		if err != nil {
+			t.Fatalf("Spawn(%q) error = %v", test.name, err)
+		}
+		if position, _ := Get[testPosition](registry, entity); position != test.position {
+			t.Errorf("Spawn(%q) position = %v, want %v", test.name, position, test.position)
+		}
+		if Has[testTag](registry, entity) != test.tagged {
+			t.Errorf("Spawn(%q) tagged = %t, want %t", test.name, !test.tagged, test.tagged)
+		}
+	}
+}
+
+// TestPrefabErrors checks that unknown prefabs, cycles and unknown component
+// types are reported and spawn nothing.
+func TestPrefabErrors(t *testing.T) {
+	library := newTestPrefabs(t)
+	library.Add("first", []byte(`{"extends": "second", "components": {}}`))
+	library.Add("second", []byte(`{"extends": "first", "components": {}}`))
+	library.Add("orphan", []byte(`{"extends": "missing", "components": {}}`))
+	library.Add("unknown", []byte(`{"extends": "base", "components": {"velocity": {"DX": 1}}}`))
+	library.Add("invalid", []byte(`{"components": {"position": {"X": "left"}}}`))
+	if err := library.Add("broken", []byte(`{"components":`)); err == nil {
+		t.Error("Add() of malformed JSON error = nil, want an error")
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"missing", `core: unknown prefab "missing"`},
+		{"orphan", `core: unknown prefab "missing"`},
+		{"first", `core: prefabs extend each other in a cycle: first -> second -> first`},
+		{"unknown", `core: prefab "unknown": core: unknown serializable type "velocity"`},
+		{"invalid", `core: prefab "invalid": decoding "position"`},
+	}
+	registry := NewRegistry()
+	for _, test := range tests {
+		_, err := library.Spawn(registry, test.name)
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("Spawn(%q) error = %v, want %s", test.name, err, test.want)
+		}
+	}
+	if len(registry.archetypes[0].entities) != 0 {
+		t.Error("a failed spawn created entities")
+	}
+}
@@ -0,0 +1,570 @@
+package core
+
+import "reflect"
+
+// filterKind selects how a QueryFilter restricts the matched entities.
+type filterKind int
+
+const (
+	filterWith filterKind = iota
+	filterWithout
+	filterAdded
+	filterChanged
+	filterRelated
+)
+
+// QueryFilter restricts the entities yielded by a query beyond the component
+// types the query requests.
+type QueryFilter struct {
+	identifier reflect.Type
+	kind       filterKind
+	since      Tick
+	target     Entity
+}
+
+// With creates a filter matching only entities that own a component of type T.
+//
+// Returns:
+//
+//	QueryFilter: The filter requiring a component of type T.
+func With[T any]() QueryFilter {
+	return QueryFilter{identifier: componentType[T](), kind: filterWith}
+}
+
+// Without creates a filter matching only entities that do not own a component of type T.
+//
+// Returns:
+//
+//	QueryFilter: The filter excluding entities with a component of type T.
+func Without[T any]() QueryFilter {
+	return QueryFilter{identifier: componentType[T](), kind: filterWithout}
+}
+
+// Query1 yields every entity owning a component of type A.
+type Query1[A any] struct {
+	registry *Registry
+	filters  []QueryFilter
+}
+
+// NewQuery1 creates a query over entities owning a component of type A.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to query.
+//	filters (...QueryFilter): Additional filters an entity must satisfy.
+//
+// Returns:
+//
+//	*Query1[A]: A pointer to the newly created query.
+func NewQuery1[A any](registry *Registry, filters ...QueryFilter) *Query1[A] {
+	return &Query1[A]{registry: registry, filters: filters}
+}
+
+// Each calls fn for every entity matched by the query.
+//
+// Parameters:
+//
+//	fn (func(Entity, A)): The function invoked with each entity and its component.
+func (q *Query1[A]) Each(fn func(entity Entity, a A)) {
+	if infos := q.infos(); infos != nil {
+		q.registry.eachMatch(infos, q.filters, func(batch queryBatch) {
+			q.visit(infos, batch, fn)
+		})
+	}
+}
+
+// ParallelForEach calls fn for every entity matched by the query, splitting the
+// entities into batches of batchSize that the registry's worker pool processes
+// concurrently. Inside fn it is safe to read components with Get and Has, to
+// modify the components passed to fn, to mark them with MarkChanged and to record
+// into Commands. Structural changes through the registry itself, such as
+// NewEntity, DestroyEntity, Add and Remove, are not safe and must be deferred
+// through Commands.
+//
+// Parameters:
+//
+//	batchSize (int): The number of entities per batch; DefaultBatchSize if not positive.
+//	fn (func(Entity, A)): The function invoked with each entity and its component.
+func (q *Query1[A]) ParallelForEach(batchSize int, fn func(entity Entity, a A)) {
+	if infos := q.infos(); infos != nil {
+		q.registry.parallelMatch(infos, q.filters, batchSize, func(batch queryBatch) {
+			q.visit(infos, batch, fn)
+		})
+	}
+}
+
+// infos resolves the component types requested by the query.
+//
+// Returns:
+//
+//	[]*componentInfo: The infos in request order, or nil if any type was never added.
+func (q *Query1[A]) infos() []*componentInfo {
+	return q.registry.queryInfos(componentType[A]())
+}
+
+// visit calls fn for every entity of a batch.
+//
+// Parameters:
+//
+//	infos ([]*componentInfo): The component types requested by the query.
+//	batch (queryBatch): The batch of matching entities.
+//	fn (func(Entity, A)): The function invoked with each entity and its component.
+func (q *Query1[A]) visit(infos []*componentInfo, batch queryBatch, fn func(entity Entity, a A)) {
+	fetchA := fetcher[A]{info: infos[0]}
+	as := fetchA.column(batch.arch)
+	if as != nil && batch.rows == nil {
+		for idx, entity := range batch.entities {
+			row := batch.offset + idx
+			fn(entity, as[row])
+		}
+		return
+	}
+	for idx, entity := range batch.entities {
+		row := batch.row(idx)
+		fn(entity, fetchA.get(as, entity, row))
+	}
+}
+
+// Query2 yields every entity owning components of both type A and type B.
+type Query2[A, B any] struct {
+	registry *Registry
+	filters  []QueryFilter
+}
+
+// NewQuery2 creates a query over entities owning components of type A and B.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to query.
+//	filters (...QueryFilter): Additional filters an entity must satisfy.
+//
+// Returns:
+//
+//	*Query2[A, B]: A pointer to the newly created query.
+func NewQuery2[A, B any](registry *Registry, filters ...QueryFilter) *Query2[A, B] {
+	return &Query2[A, B]{registry: registry, filters: filters}
+}
+
+// Each calls fn for every entity matched by the query.
+//
+// Parameters:
+//
+//	fn (func(Entity, A, B)): The function invoked with each entity and its components.
+func (q *Query2[A, B]) Each(fn func(entity Entity, a A, b B)) {
+	if infos := q.infos(); infos != nil {
+		q.registry.eachMatch(infos, q.filters, func(batch queryBatch) {
+			q.visit(infos, batch, fn)
+		})
+	}
+}
+
+// ParallelForEach calls fn for every entity matched by the query, splitting the
+// entities into batches of batchSize that the registry's worker pool processes
+// concurrently. Inside fn it is safe to read components with Get and Has, to
+// modify the components passed to fn, to mark them with MarkChanged and to record
+// into Commands. Structural changes through the registry itself, such as
+// NewEntity, DestroyEntity, Add and Remove, are not safe and must be deferred
+// through Commands.
+//
+// Parameters:
+//
+//	batchSize (int): The number of entities per batch; DefaultBatchSize if not positive.
+//	fn (func(Entity, A, B)): The function invoked with each entity and its components.
+func (q *Query2[A, B]) ParallelForEach(batchSize int, fn func(entity Entity, a A, b B)) {
+	if infos := q.infos(); infos != nil {
+		q.registry.parallelMatch(infos, q.filters, batchSize, func(batch queryBatch) {
+			q.visit(infos, batch, fn)
+		})
+	}
+}
+
+// infos resolves the component types requested by the query.
+//
+// Returns:
+//
+//	[]*componentInfo: The infos in request order, or nil if any type was never added.
+func (q *Query2[A, B]) infos() []*componentInfo {
+	return q.registry.queryInfos(componentType[A](), componentType[B]())
+}
+
+// visit calls fn for every entity of a batch.
+//
+// Parameters:
+//
+//	infos ([]*componentInfo): The component types requested by the query.
+//	batch (queryBatch): The batch of matching entities.
+//	fn (func(Entity, A, B)): The function invoked with each entity and its components.
+func (q *Query2[A, B]) visit(infos []*componentInfo, batch queryBatch, fn func(entity Entity, a A, b B)) {
+	fetchA := fetcher[A]{info: infos[0]}
+	fetchB := fetcher[B]{info: infos[1]}
+	as, bs := fetchA.column(batch.arch), fetchB.column(batch.arch)
+	if as != nil && bs != nil && batch.rows == nil {
+		for idx, entity := range batch.entities {
+			row := batch.offset + idx
+			fn(entity, as[row], bs[row])
+		}
+		return
+	}
+	for idx, entity := range batch.entities {
+		row := batch.row(idx)
+		fn(entity, fetchA.get(as, entity, row), fetchB.get(bs, entity, row))
+	}
+}
+
+// Query3 yields every entity owning components of type A, B and C.
+type Query3[A, B, C any] struct {
+	registry *Registry
+	filters  []QueryFilter
+}
+
+// NewQuery3 creates a query over entities owning components of type A, B and C.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to query.
+//	filters (...QueryFilter): Additional filters an entity must satisfy.
+//
+// Returns:
+//
+//	*Query3[A, B, C]: A pointer to the newly created query.
+func NewQuery3[A, B, C any](registry *Registry, filters ...QueryFilter) *Query3[A, B, C] {
+	return &Query3[A, B, C]{registry: registry, filters: filters}
+}
+
+// Each calls fn for every entity matched by the query.
+//
+// Parameters:
+//
+//	fn (func(Entity, A, B, C)): The function invoked with each entity and its components.
+func (q *Query3[A, B, C]) Each(fn func(entity Entity, a A, b B, c C)) {
+	if infos := q.infos(); infos != nil {
+		q.registry.eachMatch(infos, q.filters, func(batch queryBatch) {
+			q.visit(infos, batch, fn)
+		})
+	}
+}
+
+// ParallelForEach calls fn for every entity matched by the query, splitting the
+// entities into batches of batchSize that the registry's worker pool processes
+// concurrently. Inside fn it is safe to read components with Get and Has, to
+// modify the components passed to fn, to mark them with MarkChanged and to record
+// into Commands. Structural changes through the registry itself, such as
+// NewEntity, DestroyEntity, Add and Remove, are not safe and must be deferred
+// through Commands.
+//
+// Parameters:
+//
+//	batchSize (int): The number of entities per batch; DefaultBatchSize if not positive.
+//	fn (func(Entity, A, B, C)): The function invoked with each entity and its components.
+func (q *Query3[A, B, C]) ParallelForEach(batchSize int, fn func(entity Entity, a A, b B, c C)) {
+	if infos := q.infos(); infos != nil {
+		q.registry.parallelMatch(infos, q.filters, batchSize, func(batch queryBatch) {
+			q.visit(infos, batch, fn)
+		})
+	}
+}
+
+// infos resolves the component types requested by the query.
+//
+// Returns:
+//
+//	[]*componentInfo: The infos in request order, or nil if any type was never added.
+func (q *Query3[A, B, C]) infos() []*componentInfo {
+	return q.registry.queryInfos(componentType[A](), componentType[B](), componentType[C]())
+}
+
+// visit calls fn for every entity of a batch.
+//
+// Parameters:
+//
+//	infos ([]*componentInfo): The component types requested by the query.
+//	batch (queryBatch): The batch of matching entities.
+//	fn (func(Entity, A, B, C)): The function invoked with each entity and its components.
+func (q *Query3[A, B, C]) visit(infos []*componentInfo, batch queryBatch, fn func(entity Entity, a A, b B, c C)) {
+	fetchA := fetcher[A]{info: infos[0]}
+	fetchB := fetcher[B]{info: infos[1]}
+	fetchC := fetcher[C]{info: infos[2]}
+	as, bs, cs := fetchA.column(batch.arch), fetchB.column(batch.arch), fetchC.column(batch.arch)
+	if as != nil && bs != nil && cs != nil && batch.rows == nil {
+		for idx, entity := range batch.entities {
+			row := batch.offset + idx
+			fn(entity, as[row], bs[row], cs[row])
+		}
+		return
+	}
+	for idx, entity := range batch.entities {
+		row := batch.row(idx)
+		fn(entity, fetchA.get(as, entity, row), fetchB.get(bs, entity, row), fetchC.get(cs, entity, row))
+	}
+}
+
+// fetcher reads the components of type T of the entities visited by a query.
+// Typed columns are read through their backing slice; boxed and tag columns are
+// read row by row, so that visiting them never copies a whole column.
+type fetcher[T any] struct {
+	info *componentInfo
+	col  column
+}
+
+// column resolves the column of an archetype for table component types and
+// returns its backing slice if it is a typed column.
+//
+// Parameters:
+//
+//	arch (*archetype): The archetype being visited.
+//
+// Returns:
+//
+//	[]T: The components in row order, or nil for boxed, tag and sparse-set columns.
+func (f *fetcher[T]) column(arch *archetype) []T {
+	f.col = nil
+	if f.info.sparse != nil {
+		return nil
+	}
+	f.col = arch.column(f.info.id)
+	if typed, ok := f.col.(*typedColumn[T]); ok {
+		return typed.data
+	}
+	return nil
+}
+
+// get returns the component of type T of a visited entity.
+//
+// Parameters:
+//
+//	data ([]T): The typed column returned by column for the visited archetype.
+//	entity (Entity): The visited entity.
+//	row (int): The row of the entity within the archetype.
+//
+// Returns:
+//
+//	T: The component of the entity.
+func (f *fetcher[T]) get(data []T, entity Entity, row int) T {
+	if data != nil {
+		return data[row]
+	}
+	if f.col != nil {
+		return columnValue[T](f.col, row)
+	}
+	sparseRow, _ := f.info.sparse.row(entity)
+	return columnValue[T](f.info.sparse.data, sparseRow)
+}
+
+// queryBatch is a run of matching entities of a single archetype. If rows is nil,
+// the entities occupy consecutive rows of the archetype starting at offset, which
+// lets queries driven by a sparse set visit single entities without allocating.
+type queryBatch struct {
+	arch     *archetype
+	entities []Entity
+	rows     []int
+	offset   int
+}
+
+// row returns the archetype row of the idx-th entity of the batch.
+//
+// Parameters:
+//
+//	idx (int): The position of the entity within the batch.
+//
+// Returns:
+//
+//	int: The row of the entity within its archetype.
+func (b queryBatch) row(idx int) int {
+	if b.rows == nil {
+		return b.offset + idx
+	}
+	return b.rows[idx]
+}
+
+// queryInfos resolves the component types requested by a query.
+//
+// Parameters:
+//
+//	identifiers (...reflect.Type): The requested component types.
+//
+// Returns:
+//
+//	[]*componentInfo: The infos in request order, or nil if any type was never added.
+func (r *Registry) queryInfos(identifiers ...reflect.Type) []*componentInfo {
+	infos := make([]*componentInfo, len(identifiers))
+	for idx, identifier := range identifiers {
+		if infos[idx] = r.componentInfo(identifier); infos[idx] == nil {
+			return nil
+		}
+	}
+	return infos
+}
+
+// eachMatch calls visit with every batch of entities owning all requested
+// component types and satisfying every filter. If a requested type uses
+// sparse-set storage, the smallest such set drives the iteration; otherwise
+// matching archetypes are visited as a whole, or entity by entity if a filter
+// cannot be decided for the whole archetype.
+//
+// Parameters:
+//
+//	infos ([]*componentInfo): The requested component types.
+//	filters ([]QueryFilter): The filters to apply.
+//	visit (func(queryBatch)): Invoked with each batch of matching entities.
+func (r *Registry) eachMatch(infos []*componentInfo, filters []QueryFilter, visit func(batch queryBatch)) {
+	var driver *sparseSet
+	for _, info := range infos {
+		if info.sparse != nil && (driver == nil || len(info.sparse.entities) < len(driver.entities)) {
+			driver = info.sparse
+		}
+	}
+
+	if driver != nil {
+		var last *archetype
+		lastMatches := false
+		for idx, entity := range driver.entities {
+			record := r.records[entity.Index()]
+			if record.archetype != last {
+				last, lastMatches = record.archetype, record.archetype.matches(r, infos, filters)
+			}
+			if lastMatches && r.matchesEntity(entity, record, infos, filters) {
+				visit(queryBatch{arch: record.archetype, entities: driver.entities[idx : idx+1], offset: record.row})
+			}
+		}
+		return
+	}
+
+	checkEntities := r.hasEntityFilter(filters)
+	for _, arch := range r.archetypes {
+		if len(arch.entities) == 0 || !arch.matches(r, infos, filters) {
+			continue
+		}
+		if !checkEntities {
+			visit(queryBatch{arch: arch, entities: arch.entities})
+			continue
+		}
+		var entities []Entity
+		var rows []int
+		for row, entity := range arch.entities {
+			if r.matchesEntity(entity, entityRecord{archetype: arch, row: row}, infos, filters) {
+				entities = append(entities, entity)
+				rows = append(rows, row)
+			}
+		}
+		if len(entities) > 0 {
+			visit(queryBatch{arch: arch, entities: entities, rows: rows})
+		}
+	}
+}
+
+// hasEntityFilter reports whether any filter has to be checked per entity, either
+// because it refers to a sparse-set component type, compares ticks or requires a
+// relationship.
+//
+// Parameters:
+//
+//	filters ([]QueryFilter): The filters to check.
+//
+// Returns:
+//
+//	bool: True if a filter has to be checked per entity.
+func (r *Registry) hasEntityFilter(filters []QueryFilter) bool {
+	for _, filter := range filters {
+		if filter.kind == filterAdded || filter.kind == filterChanged || filter.kind == filterRelated {
+			return true
+		}
+		if info := r.componentInfo(filter.identifier); info != nil && info.sparse != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesEntity reports whether an entity satisfies the requested component types
+// and filters that refer to sparse-set component types, and every filter that
+// compares ticks or requires a relationship.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to check.
+//	record (entityRecord): The archetype and row of the entity.
+//	infos ([]*componentInfo): The requested component types.
+//	filters ([]QueryFilter): The filters to apply.
+//
+// Returns:
+//
+//	bool: True if the entity matches.
+func (r *Registry) matchesEntity(entity Entity, record entityRecord, infos []*componentInfo, filters []QueryFilter) bool {
+	for _, info := range infos {
+		if info.sparse != nil && !info.sparse.has(entity) {
+			return false
+		}
+	}
+	for _, filter := range filters {
+		if filter.kind == filterRelated {
+			index := r.relations[filter.identifier]
+			if index == nil || !index.matches(entity, filter.target) {
+				return false
+			}
+			continue
+		}
+		info := r.componentInfo(filter.identifier)
+		if info == nil {
+			continue
+		}
+		ticks, row := record.archetype.columnTicks(info.id), record.row
+		if info.sparse != nil {
+			var ok bool
+			if row, ok = info.sparse.row(entity); ok {
+				ticks = &info.sparse.ticks
+			} else {
+				ticks = nil
+			}
+		}
+		switch filter.kind {
+		case filterWith, filterWithout:
+			if info.sparse != nil && (ticks != nil) == (filter.kind == filterWithout) {
+				return false
+			}
+		case filterAdded:
+			if ticks == nil || ticks.added[row] <= filter.since {
+				return false
+			}
+		case filterChanged:
+			if ticks == nil || ticks.changed[row] <= filter.since {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matches reports whether the archetype contains every requested table component
+// type and satisfies every filter on table component types. Sparse-set types are
+// checked per entity by matchesEntity, as are the ticks compared by Added and
+// Changed filters and the relationships required by RelatedTo filters.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry owning the archetype.
+//	infos ([]*componentInfo): The requested component types.
+//	filters ([]QueryFilter): The filters to apply.
+//
+// Returns:
+//
+//	bool: True if the archetype matches.
+func (a *archetype) matches(registry *Registry, infos []*componentInfo, filters []QueryFilter) bool {
+	for _, info := range infos {
+		if info.sparse == nil && !a.has(info.id) {
+			return false
+		}
+	}
+	for _, filter := range filters {
+		if filter.kind == filterRelated {
+			continue
+		}
+		info := registry.componentInfo(filter.identifier)
+		if info != nil && info.sparse != nil {
+			continue
+		}
+		if (info != nil && a.has(info.id)) == (filter.kind == filterWithout) {
+			return false
+		}
+	}
+	return true
+}
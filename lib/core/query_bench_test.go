@@ -0,0 +1,75 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// benchmarkEntities is the number of entities owning both queried components.
+const benchmarkEntities = 10000
+
+// mapStorage reproduces the previous component layout of Registry, where every
+// component lived in map[reflect.Type]map[Entity]Component.
+type mapStorage map[reflect.Type]map[Entity]Component
+
+// BenchmarkQuery2Archetype measures a two-component query pass over the
+// archetype storage.
+func BenchmarkQuery2Archetype(b *testing.B) {
+	registry := NewRegistry()
+	for i := 0; i < benchmarkEntities; i++ {
+		entity := registry.NewEntity()
+		Add(registry, entity, &testPosition{})
+		Add(registry, entity, &testVelocity{DX: 1, DY: 1})
+	}
+	query := NewQuery2[*testPosition, *testVelocity](registry)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		query.Each(func(_ Entity, position *testPosition, velocity *testVelocity) {
+			position.X += velocity.DX
+		})
+	}
+	b.ReportMetric(float64(benchmarkEntities*b.N)/b.Elapsed().Seconds(), "entities/s")
+}
+
+// BenchmarkQuery2MapStorage measures the same pass over the previous map
+// storage as a baseline.
+func BenchmarkQuery2MapStorage(b *testing.B) {
+	positionType := reflect.TypeOf(&testPosition{})
+	velocityType := reflect.TypeOf(&testVelocity{})
+	storage := mapStorage{
+		positionType: make(map[Entity]Component),
+		velocityType: make(map[Entity]Component),
+	}
+	for i := 0; i < benchmarkEntities; i++ {
+		entity := newEntity(uint32(i), 1)
+		storage[positionType][entity] = &testPosition{}
+		storage[velocityType][entity] = &testVelocity{DX: 1, DY: 1}
+	}
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for entity, component := range storage[positionType] {
+			position := component.(*testPosition)
+			if velocity, ok := storage[velocityType][entity].(*testVelocity); ok {
+				position.X += velocity.DX
+			}
+		}
+	}
+	b.ReportMetric(float64(benchmarkEntities*b.N)/b.Elapsed().Seconds(), "entities/s")
+}
+
+// BenchmarkQuery2SparseBoxed measures a query driven by a sparse set over a boxed
+// column, which is visited entity by entity.
+func BenchmarkQuery2SparseBoxed(b *testing.B) {
+	registry := newMixedRegistry(b, benchmarkEntities)
+	query := NewQuery2[*testPosition, *testVelocity](registry)
+
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		query.Each(func(_ Entity, position *testPosition, velocity *testVelocity) {
+			position.X += velocity.DX
+		})
+	}
+	b.ReportMetric(float64(benchmarkEntities*b.N)/b.Elapsed().Seconds(), "entities/s")
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"sync"
+	"testing"
+)
+
+// testTag is a zero-size component stored in tag columns.
+type testTag struct{}
+
+// newMixedRegistry creates a registry whose positions are stored in a boxed column
+// and whose velocities are stored in a sparse set, so that the velocities drive
+// queries over both. Every even entity also owns a testTag.
+func newMixedRegistry(t testing.TB, entities int) *Registry {
+	t.Helper()
+	registry := NewRegistry()
+	if err := RegisterComponent[*testVelocity](registry, StorageSparseSet); err != nil {
+		t.Fatalf("RegisterComponent() error = %v", err)
+	}
+	for i := 0; i < entities; i++ {
+		entity := registry.NewEntity()
+		registry.AddComponent(entity, &testPosition{X: float64(i)})
+		Add(registry, entity, &testVelocity{DX: 1})
+		if i%2 == 0 {
+			Add(registry, entity, testTag{})
+		}
+	}
+	return registry
+}
+
+// TestQueryReadsBoxedColumnsPerRow checks that queries driven by a sparse set
+// read the matching rows of boxed and tag columns.
+func TestQueryReadsBoxedColumnsPerRow(t *testing.T) {
+	registry := newMixedRegistry(t, 100)
+
+	visited := 0
+	NewQuery2[*testPosition, *testVelocity](registry).Each(func(entity Entity, position *testPosition, velocity *testVelocity) {
+		if position.X != float64(entity.Index()) {
+			t.Errorf("%v position = %v, want X %d", entity, position, entity.Index())
+		}
+		position.X += velocity.DX
+		visited++
+	})
+	if visited != 100 {
+		t.Fatalf("visited %d entities, want 100", visited)
+	}
+
+	tagged := 0
+	NewQuery3[*testPosition, *testVelocity, testTag](registry).Each(func(entity Entity, position *testPosition, _ *testVelocity, _ testTag) {
+		if entity.Index()%2 != 0 || position.X != float64(entity.Index())+1 {
+			t.Errorf("%v position = %v, want an even entity moved by 1", entity, position)
+		}
+		tagged++
+	})
+	if tagged != 50 {
+		t.Errorf("visited %d tagged entities, want 50", tagged)
+	}
+}
+
+// TestSparseQueryDoesNotAllocatePerEntity checks that a query driven by a sparse
+// set visits its entities without allocating for each of them.
+func TestSparseQueryDoesNotAllocatePerEntity(t *testing.T) {
+	registry := newMixedRegistry(t, 1000)
+	query := NewQuery2[*testPosition, *testVelocity](registry)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		query.Each(func(_ Entity, position *testPosition, velocity *testVelocity) {
+			position.X += velocity.DX
+		})
+	})
+	if allocs > 1 {
+		t.Errorf("Each() allocated %v times, want at most 1", allocs)
+	}
+}
+
+// TestParallelForEachReadsBoxedColumnsPerRow checks ParallelForEach over the same
+// mix of storages.
+func TestParallelForEachReadsBoxedColumnsPerRow(t *testing.T) {
+	registry := newMixedRegistry(t, 1000)
+
+	var mutex sync.Mutex
+	visited := make(map[Entity]bool)
+	NewQuery2[*testPosition, *testVelocity](registry).ParallelForEach(16, func(entity Entity, position *testPosition, _ *testVelocity) {
+		if position.X != float64(entity.Index()) {
+			t.Errorf("%v position = %v, want X %d", entity, position, entity.Index())
+		}
+		mutex.Lock()
+		visited[entity] = true
+		mutex.Unlock()
+	})
+	if len(visited) != 1000 {
+		t.Errorf("visited %d entities, want 1000", len(visited))
+	}
+}
@@ -1,17 +1,39 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
-	"slices"
+	"sync"
 )
 
 // Registry manages entities, components, and systems within the ECS architecture.
 // It provides methods to add entities, components, and systems, and to update systems.
+//
+// Components are stored in archetypes: every distinct set of component types owned
+// by an entity gets its own table of contiguous, typed columns, so that queries
+// iterate packed slices instead of hash maps. Component types registered with
+// StorageSparseSet are kept in sparse sets outside the archetypes instead.
 type Registry struct {
-	nextEntityId int
-	systems      map[reflect.Type]System
-	components   map[reflect.Type]map[Entity]Component
+	generations    []uint32
+	allocator      entityAllocator
+	records        []entityRecord
+	componentInfos map[reflect.Type]*componentInfo
+	sparseInfos    []*componentInfo
+	archetypes     []*archetype
+	archetypeIndex map[string]*archetype
+	phases         map[Phase]*scheduler
+	workers        int
+	tick           Tick
+	frameStart     Tick
+	removed        map[reflect.Type][]removal
+	hooks          map[reflect.Type]*componentHooks
+	destroying     map[Entity]bool
+	eventsMutex    sync.Mutex
+	events         map[reflect.Type]eventQueue
+	resourcesMutex sync.RWMutex
+	resources      map[reflect.Type]interface{}
+	relations      map[reflect.Type]*relationIndex
 }
 
 // NewRegistry creates and returns a new instance of Registry.
@@ -20,38 +42,275 @@ type Registry struct {
 //
 //	*Registry: A pointer to the newly created Registry instance.
 func NewRegistry() *Registry {
-	return &Registry{
-		nextEntityId: 0,
-		systems:      make(map[reflect.Type]System),
-		components:   make(map[reflect.Type]map[int]interface{}),
+	registry := &Registry{
+		componentInfos: make(map[reflect.Type]*componentInfo),
+		archetypeIndex: make(map[string]*archetype),
+		phases:         make(map[Phase]*scheduler),
+		tick:           1,
+		removed:        make(map[reflect.Type][]removal),
+		hooks:          make(map[reflect.Type]*componentHooks),
+		destroying:     make(map[Entity]bool),
+		events:         make(map[reflect.Type]eventQueue),
+		resources:      make(map[reflect.Type]interface{}),
+		relations:      make(map[reflect.Type]*relationIndex),
 	}
+	registry.archetypeOf(nil)
+	return registry
 }
 
-// NewEntity creates a new entity and returns its identifier.
+// NewEntity creates a new entity without components and returns its identifier.
+// Slots of destroyed entities are recycled with a bumped generation.
 //
 // Returns:
 //
 //	Entity: The identifier of the newly created entity.
 func (r *Registry) NewEntity() Entity {
-	r.nextEntityId++
-	return r.nextEntityId
+	entity := r.reserveEntity()
+	r.spawnReserved(entity)
+	return entity
 }
 
-// AddComponent adds a component to a specified entity.
+// reserveEntity allocates the identifier of a new entity without creating it.
+// It is safe to call from systems running concurrently.
+//
+// Returns:
+//
+//	Entity: The reserved identifier.
+func (r *Registry) reserveEntity() Entity {
+	r.allocator.mutex.Lock()
+	defer r.allocator.mutex.Unlock()
+
+	if free := len(r.allocator.freeIndices); free > 0 {
+		index := r.allocator.freeIndices[free-1]
+		r.allocator.freeIndices = r.allocator.freeIndices[:free-1]
+		return newEntity(index, r.generations[index])
+	}
+	index := r.allocator.nextIndex
+	r.allocator.nextIndex++
+	return newEntity(index, 1)
+}
+
+// spawnReserved creates a previously reserved entity without components.
+//
+// Parameters:
+//
+//	entity (Entity): The reserved entity.
+func (r *Registry) spawnReserved(entity Entity) {
+	index := int(entity.Index())
+	for len(r.generations) <= index {
+		r.generations = append(r.generations, 1)
+		r.records = append(r.records, entityRecord{})
+	}
+
+	empty := r.archetypes[0]
+	empty.entities = append(empty.entities, entity)
+	r.records[index] = entityRecord{archetype: empty, row: len(empty.entities) - 1}
+}
+
+// DestroyEntity destroys an entity and removes all of its components from the
+// registry. The descendants of the entity are destroyed first, and relationships
+// from or to the entity are removed. The remove hooks of the components run
+// before the entity is destroyed; destroying the entity again from within those
+// hooks has no effect.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to be destroyed.
+//
+// Returns:
+//
+//	bool: True if the entity was alive and has been destroyed.
+func (r *Registry) DestroyEntity(entity Entity) bool {
+	return r.destroyEntity(entity, true)
+}
+
+// destroyEntity destroys an entity and its descendants.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to be destroyed.
+//	hooks (bool): Whether the remove hooks of the components run.
+//
+// Returns:
+//
+//	bool: True if the entity was alive and has been destroyed.
+func (r *Registry) destroyEntity(entity Entity, hooks bool) bool {
+	if !r.IsAlive(entity) || r.destroying[entity] {
+		return false
+	}
+	r.destroying[entity] = true
+	r.destroyHierarchy(entity, hooks)
+	if hooks {
+		r.runRemoveHooks(entity)
+	}
+	delete(r.destroying, entity)
+	r.removeRelations(entity)
+
+	index := entity.Index()
+	record := r.records[index]
+	for _, info := range record.archetype.components {
+		r.recordRemoval(info.identifier, entity)
+	}
+	r.removeRow(record.archetype, record.row)
+	r.records[index] = entityRecord{}
+	for _, info := range r.sparseInfos {
+		if info.sparse.remove(entity) {
+			r.recordRemoval(info.identifier, entity)
+		}
+	}
+
+	r.generations[index]++
+	if r.generations[index] == 0 {
+		// Generation 0 is reserved so that NilEntity is never alive.
+		r.generations[index] = 1
+	}
+	r.allocator.mutex.Lock()
+	r.allocator.freeIndices = append(r.allocator.freeIndices, index)
+	r.allocator.mutex.Unlock()
+	return true
+}
+
+// IsAlive reports whether an entity has been created and not yet destroyed. Stale
+// handles whose slot has since been recycled, and entities reserved by Commands
+// that have not been flushed yet, are reported as not alive.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to check.
+//
+// Returns:
+//
+//	bool: True if the entity is alive.
+func (r *Registry) IsAlive(entity Entity) bool {
+	index := int(entity.Index())
+	return index < len(r.generations) &&
+		r.generations[index] == entity.Generation() &&
+		r.records[index].archetype != nil
+}
+
+// AddComponent adds a component to a specified entity. Components added to
+// entities that are not alive and nil components are discarded. Prefer the generic
+// Add, which stores components in typed columns.
 //
 // Parameters:
 //
 //	entity (Entity): The entity to which the component is added.
 //	component (Component): The component to be added.
 func (r *Registry) AddComponent(entity Entity, component Component) {
-	identifier := reflect.TypeOf(component)
-	if r.components[identifier] == nil {
-		r.components[identifier] = make(map[int]interface{})
+	if component == nil {
+		return
+	}
+	r.addComponent(r.registerComponent(reflect.TypeOf(component), nil), entity, component)
+}
+
+// addComponent stores a component for an entity, moving the entity to the
+// archetype including the component type if needed, and invokes the add or
+// replace hooks.
+//
+// Parameters:
+//
+//	info (*componentInfo): The type under which the component is stored.
+//	entity (Entity): The entity to which the component is added.
+//	component (Component): The component to be added.
+func (r *Registry) addComponent(info *componentInfo, entity Entity, component Component) {
+	if !r.IsAlive(entity) {
+		return
 	}
-	r.components[identifier][entity] = component
+	if info.sparse != nil {
+		if row, ok := info.sparse.row(entity); ok {
+			r.replaceComponent(info, info.sparse.data, &info.sparse.ticks, row, entity, component)
+			return
+		}
+		info.sparse.insert(entity, component, r.tick)
+	} else {
+		record := &r.records[entity.Index()]
+		if existing := record.archetype.column(info.id); existing != nil {
+			r.replaceComponent(info, existing, record.archetype.columnTicks(info.id), record.row, entity, component)
+			return
+		}
+		target := r.archetypeWith(record.archetype, info)
+		r.moveEntity(entity, target)
+		target.column(info.id).push(component)
+		target.columnTicks(info.id).push(r.tick)
+	}
+	info.hooks.added(r, entity, component)
 }
 
-// GetAllComponentsOfType returns all components of a specified type.
+// replaceComponent overwrites the component stored in a row, marks it as changed
+// and invokes the replace hooks.
+//
+// Parameters:
+//
+//	info (*componentInfo): The type under which the component is stored.
+//	col (column): The column holding the component.
+//	ticks (*componentTicks): The change ticks of the column, nil for tags.
+//	row (int): The row of the component.
+//	entity (Entity): The entity owning the component.
+//	component (Component): The new component.
+func (r *Registry) replaceComponent(info *componentInfo, col column, ticks *componentTicks, row int, entity Entity, component Component) {
+	var previous Component
+	if len(info.hooks.onReplace) > 0 {
+		previous = col.get(row)
+	}
+	col.set(row, component)
+	if ticks != nil {
+		ticks.changed[row] = r.tick
+	}
+	info.hooks.replaced(r, entity, previous, component)
+}
+
+// RemoveComponent removes the component of a specified type from a given entity.
+// The remove hooks run before the component is removed, unless the entity is
+// being destroyed, in which case they run for every component of the entity
+// anyway.
+//
+// Parameters:
+//
+//	componentType (reflect.Type): The type of component to remove.
+//	entity (Entity): The entity whose component is to be removed.
+//
+// Returns:
+//
+//	bool: True if the entity owned a component of the specified type.
+func (r *Registry) RemoveComponent(componentType reflect.Type, entity Entity) bool {
+	col, _, row := r.locate(componentType, entity)
+	if col == nil {
+		return false
+	}
+	info := r.componentInfos[componentType]
+	if len(info.hooks.onRemove) > 0 && !r.destroying[entity] {
+		info.hooks.removed(r, entity, col.get(row))
+	}
+	r.detachComponent(info, entity)
+	return true
+}
+
+// detachComponent removes a component from an entity without invoking hooks.
+//
+// Parameters:
+//
+//	info (*componentInfo): The type of the component to remove.
+//	entity (Entity): The entity whose component is to be removed.
+func (r *Registry) detachComponent(info *componentInfo, entity Entity) {
+	if !r.IsAlive(entity) {
+		return
+	}
+	if info.sparse != nil {
+		if !info.sparse.remove(entity) {
+			return
+		}
+	} else {
+		record := r.records[entity.Index()]
+		if !record.archetype.has(info.id) {
+			return
+		}
+		r.moveEntity(entity, r.archetypeWithout(record.archetype, info))
+	}
+	r.recordRemoval(info.identifier, entity)
+}
+
+// GetAllComponentsOfType returns all components of a specified type. The map is
+// built on every call; use queries to iterate components in hot paths.
 //
 // Parameters:
 //
@@ -61,7 +320,25 @@ func (r *Registry) AddComponent(entity Entity, component Component) {
 //
 //	map[Entity]Component: A map of entities to their respective components of the specified type.
 func (r *Registry) GetAllComponentsOfType(componentType reflect.Type) map[Entity]Component {
-	return r.components[componentType]
+	components := make(map[Entity]Component)
+	info := r.componentInfo(componentType)
+	if info == nil {
+		return components
+	}
+	if info.sparse != nil {
+		for row, entity := range info.sparse.entities {
+			components[entity] = info.sparse.data.get(row)
+		}
+		return components
+	}
+	for _, arch := range r.archetypes {
+		if col := arch.column(info.id); col != nil {
+			for row, entity := range arch.entities {
+				components[entity] = col.get(row)
+			}
+		}
+	}
+	return components
 }
 
 // GetComponent returns the component of a specified type for a given entity.
@@ -75,54 +352,292 @@ func (r *Registry) GetAllComponentsOfType(componentType reflect.Type) map[Entity
 //
 //	Component: The component of the specified type for the given entity.
 func (r *Registry) GetComponent(componentType reflect.Type, entity Entity) Component {
-	return r.components[componentType][entity]
+	col, _, row := r.locate(componentType, entity)
+	if col == nil {
+		return nil
+	}
+	return col.get(row)
 }
 
-// AddSystem adds a system to the registry.
+// AddSystem adds a system to the registry under a unique label, so that several
+// instances of the same system type can be registered. Options declare the phase
+// of the system, which defaults to PhaseUpdate, its priority and the systems of
+// the same phase it must run before or after.
 //
 // Parameters:
 //
+//	label (string): The unique label of the system.
 //	system (System): The system to be added.
-func (r *Registry) AddSystem(system System) {
-	if system != nil {
-		identifier := reflect.TypeOf(system)
-		r.systems[identifier] = system
-	} else {
-		fmt.Println("SYSTEM IS NIL")
+//	options (...SystemOption): The scheduling options of the system.
+//
+// Returns:
+//
+//	error: An error if the system is nil, the label is taken or the phase is PhaseRender.
+func (r *Registry) AddSystem(label string, system System, options ...SystemOption) error {
+	if system == nil {
+		return errors.New("core: system is nil")
+	}
+	entry := &systemEntry{
+		label:  label,
+		system: system,
+		phase:  PhaseUpdate,
+	}
+	for _, option := range options {
+		option(entry)
 	}
+	if entry.phase == PhaseRender {
+		return fmt.Errorf("core: system %q cannot run in %v, use AddRenderer", label, PhaseRender)
+	}
+	return r.addEntry(entry)
 }
 
-// UpdateSystems updates all systems in the registry, excluding the specified types.
+// AddRenderer adds a renderer to the registry's PhaseRender under a unique label.
 //
 // Parameters:
 //
-//	excludedSystems ([]reflect.Type): A slice of system types to be excluded from the update.
-func (r *Registry) UpdateSystems(excludedSystems []reflect.Type) {
-	for systemType, system := range r.systems {
-		if !slices.Contains(excludedSystems, systemType) {
-			system.Update(r)
+//	label (string): The unique label of the renderer.
+//	renderer (Renderer): The renderer to be added.
+//	options (...SystemOption): The scheduling options of the renderer.
+//
+// Returns:
+//
+//	error: An error if the renderer is nil or the label is taken.
+func (r *Registry) AddRenderer(label string, renderer Renderer, options ...SystemOption) error {
+	if renderer == nil {
+		return errors.New("core: renderer is nil")
+	}
+	entry := &systemEntry{
+		label:    label,
+		renderer: renderer,
+	}
+	for _, option := range options {
+		option(entry)
+	}
+	entry.phase = PhaseRender
+	return r.addEntry(entry)
+}
+
+// addEntry registers a system entry with the scheduler of its phase.
+//
+// Parameters:
+//
+//	entry (*systemEntry): The entry to register.
+//
+// Returns:
+//
+//	error: An error if a system with the same label is already registered.
+func (r *Registry) addEntry(entry *systemEntry) error {
+	if r.findEntry(entry.label) != nil {
+		return fmt.Errorf("core: system %q is already registered", entry.label)
+	}
+	entry.commands = newCommands(r)
+	if r.phases[entry.phase] == nil {
+		r.phases[entry.phase] = &scheduler{}
+	}
+	r.phases[entry.phase].add(entry)
+	return nil
+}
+
+// findEntry returns the entry registered with the given label in any phase.
+//
+// Parameters:
+//
+//	label (string): The label of the system.
+//
+// Returns:
+//
+//	*systemEntry: The entry, or nil if no system has that label.
+func (r *Registry) findEntry(label string) *systemEntry {
+	for _, scheduler := range r.phases {
+		if entry := scheduler.find(label); entry != nil {
+			return entry
 		}
 	}
+	return nil
 }
 
-// GetSystem returns the system of a specified type.
+// RemoveSystem removes the system or renderer with the given label.
 //
 // Parameters:
 //
-//	systemType (reflect.Type): The type of system to retrieve.
+//	label (string): The label of the system to remove.
 //
 // Returns:
 //
-//	System: The system of the specified type.
-func (r *Registry) GetSystem(systemType reflect.Type) System {
-	return r.systems[systemType]
+//	bool: True if a system with the label was registered.
+func (r *Registry) RemoveSystem(label string) bool {
+	for _, scheduler := range r.phases {
+		if scheduler.remove(label) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetSystemEnabled enables or disables the system or renderer with the given label.
+// Disabled systems keep their place in the schedule but are skipped when their
+// phase runs.
+//
+// Parameters:
+//
+//	label (string): The label of the system.
+//	enabled (bool): Whether the system runs.
+//
+// Returns:
+//
+//	error: An error if no system has the label.
+func (r *Registry) SetSystemEnabled(label string, enabled bool) error {
+	entry := r.findEntry(label)
+	if entry == nil {
+		return fmt.Errorf("core: system %q is not registered", label)
+	}
+	entry.disabled = !enabled
+	return nil
+}
+
+// IsSystemEnabled reports whether the system or renderer with the given label is
+// registered and enabled.
+//
+// Parameters:
+//
+//	label (string): The label of the system.
+//
+// Returns:
+//
+//	bool: True if the system is registered and enabled.
+func (r *Registry) IsSystemEnabled(label string) bool {
+	entry := r.findEntry(label)
+	return entry != nil && !entry.disabled
 }
 
-// GetSystems returns all systems in the registry.
+// BuildSchedule resolves the execution order of the systems of every phase.
+// RunPhase builds the schedule on demand; calling BuildSchedule after adding
+// systems surfaces dependency errors early.
 //
 // Returns:
 //
-//	map[reflect.Type]System: A map of all systems in the registry.
-func (r *Registry) GetSystems() map[reflect.Type]System {
-	return r.systems
+//	error: An error if a dependency is unregistered or the dependencies form a cycle.
+func (r *Registry) BuildSchedule() error {
+	for phase := PhasePreUpdate; phase <= PhaseRender; phase++ {
+		if _, err := r.schedule(phase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdvanceFrame starts a new frame. Removals reported by Removed and events sent
+// through Send are kept until the end of the frame after the one they happened
+// in, so every registry that destroys entities, removes components or sends
+// events must advance its frames, whether or not it runs any phase. A game loop
+// calls AdvanceFrame once per update, before running the update phases.
+func (r *Registry) AdvanceFrame() {
+	r.pruneRemovals()
+	r.updateEvents()
+}
+
+// RunPhase updates all systems of a phase in schedule order. Consecutive systems
+// whose declared component access does not conflict run concurrently as a stage.
+// The commands recorded by the systems of a stage are flushed right after the
+// stage has run, before the next stage starts. Running a phase does not start a
+// new frame; call AdvanceFrame once per frame for that.
+//
+// Parameters:
+//
+//	phase (Phase): The phase to run. PhaseRender is run through RunRenderPhase.
+//
+// Returns:
+//
+//	error: An error if the phase is PhaseRender or its schedule cannot be built.
+func (r *Registry) RunPhase(phase Phase) error {
+	if phase == PhaseRender {
+		return fmt.Errorf("core: %v must be run with RunRenderPhase", PhaseRender)
+	}
+	if _, err := r.schedule(phase); err != nil {
+		return err
+	}
+	if scheduler := r.phases[phase]; scheduler != nil {
+		for _, stage := range scheduler.stages {
+			r.runStage(stage)
+		}
+	}
+	return nil
+}
+
+// RunRenderPhase draws all renderers onto the given screen in schedule order.
+//
+// Parameters:
+//
+//	screen (Screen): The screen the renderers draw onto, e.g. an *ebiten.Image.
+//
+// Returns:
+//
+//	error: An error if the schedule of PhaseRender cannot be built.
+func (r *Registry) RunRenderPhase(screen Screen) error {
+	order, err := r.schedule(PhaseRender)
+	if err != nil {
+		return err
+	}
+	for _, entry := range order {
+		if !entry.disabled {
+			entry.renderer.Draw(r, screen)
+		}
+	}
+	return nil
+}
+
+// schedule returns the systems of a phase in execution order.
+//
+// Parameters:
+//
+//	phase (Phase): The phase to schedule.
+//
+// Returns:
+//
+//	[]*systemEntry: The entries in execution order.
+//	error: An error if a dependency is unregistered or the dependencies form a cycle.
+func (r *Registry) schedule(phase Phase) ([]*systemEntry, error) {
+	scheduler := r.phases[phase]
+	if scheduler == nil {
+		return nil, nil
+	}
+	order, err := scheduler.schedule()
+	if err != nil {
+		return nil, fmt.Errorf("%w in phase %v", err, phase)
+	}
+	return order, nil
+}
+
+// GetSystem returns the system with the given label.
+//
+// Parameters:
+//
+//	label (string): The label of the system to retrieve.
+//
+// Returns:
+//
+//	System: The system with the given label, or nil if none is registered or it is a renderer.
+func (r *Registry) GetSystem(label string) System {
+	if entry := r.findEntry(label); entry != nil {
+		return entry.system
+	}
+	return nil
+}
+
+// GetSystems returns the systems of all update phases, ordered by phase and
+// registration order.
+//
+// Returns:
+//
+//	[]System: A slice of all update systems in the registry.
+func (r *Registry) GetSystems() []System {
+	var systems []System
+	for _, phase := range UpdatePhases {
+		if scheduler := r.phases[phase]; scheduler != nil {
+			for _, entry := range scheduler.entries {
+				systems = append(systems, entry.system)
+			}
+		}
+	}
+	return systems
 }
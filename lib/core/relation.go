@@ -0,0 +1,275 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// relationIndex stores all pairs of a single relation kind in both directions.
+type relationIndex struct {
+	targets map[Entity][]Entity
+	sources map[Entity][]Entity
+}
+
+// Relate creates a relationship of kind R from a source entity to a target
+// entity, e.g. Relate[Targets](registry, turret, enemy). The relation kind is any
+// Go type, usually an empty struct. An entity may relate to several targets with
+// the same kind. Relationships are removed when either entity is destroyed.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding both entities.
+//	source (Entity): The entity the relationship starts from.
+//	target (Entity): The entity the relationship points to.
+//
+// Returns:
+//
+//	error: An error if either entity is not alive.
+func Relate[R any](registry *Registry, source, target Entity) error {
+	if !registry.IsAlive(source) || !registry.IsAlive(target) {
+		return fmt.Errorf("core: cannot relate %v to %v as %v, both must be alive", source, target, componentType[R]())
+	}
+	registry.relationIndex(componentType[R]()).link(source, target)
+	return nil
+}
+
+// Unrelate removes the relationship of kind R from a source entity to a target entity.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding both entities.
+//	source (Entity): The entity the relationship starts from.
+//	target (Entity): The entity the relationship points to.
+//
+// Returns:
+//
+//	bool: True if the relationship existed.
+func Unrelate[R any](registry *Registry, source, target Entity) bool {
+	index := registry.relations[componentType[R]()]
+	if index == nil || !slices.Contains(index.targets[source], target) {
+		return false
+	}
+	index.unlink(source, target)
+	return true
+}
+
+// IsRelated reports whether a relationship of kind R exists from a source entity
+// to a target entity.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding both entities.
+//	source (Entity): The entity the relationship starts from.
+//	target (Entity): The entity the relationship points to.
+//
+// Returns:
+//
+//	bool: True if the relationship exists.
+func IsRelated[R any](registry *Registry, source, target Entity) bool {
+	index := registry.relations[componentType[R]()]
+	return index != nil && slices.Contains(index.targets[source], target)
+}
+
+// Targets returns the entities a source entity relates to with kind R.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	source (Entity): The entity the relationships start from.
+//
+// Returns:
+//
+//	[]Entity: The targets in the order they were related. The slice must not be modified.
+func Targets[R any](registry *Registry, source Entity) []Entity {
+	if index := registry.relations[componentType[R]()]; index != nil {
+		return index.targets[source]
+	}
+	return nil
+}
+
+// Target returns the first entity a source entity relates to with kind R, for
+// relation kinds that have a single target per entity.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	source (Entity): The entity the relationship starts from.
+//
+// Returns:
+//
+//	Entity: The target, or NilEntity if there is none.
+//	bool: True if the entity relates to a target with kind R.
+func Target[R any](registry *Registry, source Entity) (Entity, bool) {
+	if targets := Targets[R](registry, source); len(targets) > 0 {
+		return targets[0], true
+	}
+	return NilEntity, false
+}
+
+// Sources returns the entities relating to a target entity with kind R, e.g. all
+// entities that target an enemy.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	target (Entity): The entity the relationships point to.
+//
+// Returns:
+//
+//	[]Entity: The sources in the order they were related. The slice must not be modified.
+func Sources[R any](registry *Registry, target Entity) []Entity {
+	if index := registry.relations[componentType[R]()]; index != nil {
+		return index.sources[target]
+	}
+	return nil
+}
+
+// RelatedTo creates a filter matching only entities with a relationship of kind
+// R to the given target, or to any target if the target is NilEntity.
+//
+// Parameters:
+//
+//	target (Entity): The entity the relationship must point to.
+//
+// Returns:
+//
+//	QueryFilter: The filter requiring the relationship.
+func RelatedTo[R any](target Entity) QueryFilter {
+	return QueryFilter{identifier: componentType[R](), kind: filterRelated, target: target}
+}
+
+// RelateDeferred records creating a relationship of kind R. The relationship is
+// not created if either entity is no longer alive when the commands are flushed.
+//
+// Parameters:
+//
+//	commands (*Commands): The command buffer to record into.
+//	source (Entity): The entity the relationship starts from.
+//	target (Entity): The entity the relationship points to.
+func RelateDeferred[R any](commands *Commands, source, target Entity) {
+	commands.record(func(registry *Registry) {
+		_ = Relate[R](registry, source, target)
+	})
+}
+
+// UnrelateDeferred records removing a relationship of kind R.
+//
+// Parameters:
+//
+//	commands (*Commands): The command buffer to record into.
+//	source (Entity): The entity the relationship starts from.
+//	target (Entity): The entity the relationship points to.
+func UnrelateDeferred[R any](commands *Commands, source, target Entity) {
+	commands.record(func(registry *Registry) {
+		Unrelate[R](registry, source, target)
+	})
+}
+
+// relationIndex returns the index of a relation kind, creating it if needed.
+//
+// Parameters:
+//
+//	identifier (reflect.Type): The relation kind.
+//
+// Returns:
+//
+//	*relationIndex: The index of the relation kind.
+func (r *Registry) relationIndex(identifier reflect.Type) *relationIndex {
+	index := r.relations[identifier]
+	if index == nil {
+		index = &relationIndex{
+			targets: make(map[Entity][]Entity),
+			sources: make(map[Entity][]Entity),
+		}
+		r.relations[identifier] = index
+	}
+	return index
+}
+
+// matches reports whether an entity relates to a target, or to any target if the
+// target is NilEntity.
+//
+// Parameters:
+//
+//	source (Entity): The entity the relationship starts from.
+//	target (Entity): The entity the relationship points to, or NilEntity.
+//
+// Returns:
+//
+//	bool: True if the relationship exists.
+func (i *relationIndex) matches(source, target Entity) bool {
+	if target == NilEntity {
+		return len(i.targets[source]) > 0
+	}
+	return slices.Contains(i.targets[source], target)
+}
+
+// link adds a pair to both directions of the index unless it exists already.
+//
+// Parameters:
+//
+//	source (Entity): The entity the relationship starts from.
+//	target (Entity): The entity the relationship points to.
+func (i *relationIndex) link(source, target Entity) {
+	if slices.Contains(i.targets[source], target) {
+		return
+	}
+	i.targets[source] = append(i.targets[source], target)
+	i.sources[target] = append(i.sources[target], source)
+}
+
+// unlink removes a pair from both directions of the index.
+//
+// Parameters:
+//
+//	source (Entity): The entity the relationship starts from.
+//	target (Entity): The entity the relationship points to.
+func (i *relationIndex) unlink(source, target Entity) {
+	i.targets[source] = without(i.targets[source], target)
+	if len(i.targets[source]) == 0 {
+		delete(i.targets, source)
+	}
+	i.sources[target] = without(i.sources[target], source)
+	if len(i.sources[target]) == 0 {
+		delete(i.sources, target)
+	}
+}
+
+// removeRelations removes every relationship from or to an entity that is about
+// to be destroyed.
+//
+// Parameters:
+//
+//	entity (Entity): The entity about to be destroyed.
+func (r *Registry) removeRelations(entity Entity) {
+	for _, index := range r.relations {
+		for _, target := range index.targets[entity] {
+			index.unlink(entity, target)
+		}
+		for _, source := range index.sources[entity] {
+			index.unlink(source, entity)
+		}
+	}
+}
+
+// without returns a copy of a list of entities without the given entity, so
+// that slices handed out by Targets and Sources stay unchanged.
+//
+// Parameters:
+//
+//	entities ([]Entity): The entities.
+//	entity (Entity): The entity to leave out.
+//
+// Returns:
+//
+//	[]Entity: The remaining entities.
+func without(entities []Entity, entity Entity) []Entity {
+	remaining := make([]Entity, 0, len(entities))
+	for _, other := range entities {
+		if other != entity {
+			remaining = append(remaining, other)
+		}
+	}
+	return remaining
+}
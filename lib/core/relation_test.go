@@ -0,0 +1,132 @@
+package core
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// testTargets is a second relation kind, kept apart from testLikes.
+type testTargets struct{}
+
+// TestRelationLookups checks Targets, Target, Sources and IsRelated in both
+// directions, and that relation kinds are kept apart.
+func TestRelationLookups(t *testing.T) {
+	registry := NewRegistry()
+	turret, other, enemy, boss := registry.NewEntity(), registry.NewEntity(), registry.NewEntity(), registry.NewEntity()
+	Relate[testTargets](registry, turret, enemy)
+	Relate[testTargets](registry, turret, boss)
+	Relate[testTargets](registry, turret, enemy)
+	Relate[testTargets](registry, other, enemy)
+	Relate[testLikes](registry, enemy, turret)
+
+	if got, want := Targets[testTargets](registry, turret), []Entity{enemy, boss}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Targets(turret) = %v, want %v", got, want)
+	}
+	if target, ok := Target[testTargets](registry, turret); !ok || target != enemy {
+		t.Errorf("Target(turret) = %v, %v, want %v, true", target, ok, enemy)
+	}
+	if target, ok := Target[testTargets](registry, enemy); ok || target != NilEntity {
+		t.Errorf("Target(enemy) = %v, %v, want NilEntity, false", target, ok)
+	}
+	if got, want := Sources[testTargets](registry, enemy), []Entity{turret, other}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Sources(enemy) = %v, want %v", got, want)
+	}
+	if !IsRelated[testLikes](registry, enemy, turret) || IsRelated[testTargets](registry, enemy, turret) {
+		t.Error("relation kinds are not kept apart")
+	}
+
+	dead := registry.NewEntity()
+	registry.DestroyEntity(dead)
+	if err := Relate[testTargets](registry, turret, dead); err == nil {
+		t.Error("Relate() to a destroyed entity error = nil, want an error")
+	}
+}
+
+// TestUnrelate checks that Unrelate removes a single pair from both directions
+// and leaves the slices handed out before unchanged.
+func TestUnrelate(t *testing.T) {
+	registry := NewRegistry()
+	turret, enemy, boss := registry.NewEntity(), registry.NewEntity(), registry.NewEntity()
+	Relate[testTargets](registry, turret, enemy)
+	Relate[testTargets](registry, turret, boss)
+	before := Targets[testTargets](registry, turret)
+
+	if !Unrelate[testTargets](registry, turret, enemy) {
+		t.Fatal("Unrelate() = false, want true")
+	}
+	if Unrelate[testTargets](registry, turret, enemy) {
+		t.Error("Unrelate() twice = true, want false")
+	}
+	if got, want := Targets[testTargets](registry, turret), []Entity{boss}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Targets(turret) = %v, want %v", got, want)
+	}
+	if got := Sources[testTargets](registry, enemy); len(got) != 0 {
+		t.Errorf("Sources(enemy) = %v, want none", got)
+	}
+	if want := []Entity{enemy, boss}; !reflect.DeepEqual(before, want) {
+		t.Errorf("slice returned before Unrelate = %v, want %v", before, want)
+	}
+}
+
+// TestRelationsRemovedOnDestroy checks that destroying either side of a
+// relationship removes it from both directions.
+func TestRelationsRemovedOnDestroy(t *testing.T) {
+	registry := NewRegistry()
+	turret, other, enemy := registry.NewEntity(), registry.NewEntity(), registry.NewEntity()
+	Relate[testTargets](registry, turret, enemy)
+	Relate[testTargets](registry, other, enemy)
+	Relate[testLikes](registry, enemy, turret)
+
+	registry.DestroyEntity(enemy)
+	if got := Targets[testTargets](registry, turret); len(got) != 0 {
+		t.Errorf("Targets(turret) after destroying the target = %v, want none", got)
+	}
+	if got := Sources[testLikes](registry, turret); len(got) != 0 {
+		t.Errorf("Sources(turret) after destroying the source = %v, want none", got)
+	}
+
+	Relate[testTargets](registry, turret, other)
+	registry.DestroyEntity(turret)
+	if got := Sources[testTargets](registry, other); len(got) != 0 {
+		t.Errorf("Sources(other) after destroying the source = %v, want none", got)
+	}
+
+	recycled := registry.NewEntity()
+	if got := Targets[testTargets](registry, recycled); len(got) != 0 {
+		t.Errorf("recycled entity inherited the targets %v", got)
+	}
+}
+
+// TestRelatedToFilter checks that RelatedTo matches the sources of a given target
+// and that RelatedTo(NilEntity) matches every entity with any target.
+func TestRelatedToFilter(t *testing.T) {
+	registry := NewRegistry()
+	first, second, idle, enemy, boss := registry.NewEntity(), registry.NewEntity(), registry.NewEntity(), registry.NewEntity(), registry.NewEntity()
+	for _, entity := range []Entity{first, second, idle} {
+		Add(registry, entity, testPosition{})
+	}
+	Relate[testTargets](registry, first, enemy)
+	Relate[testTargets](registry, second, boss)
+	Relate[testLikes](registry, idle, enemy)
+
+	matched := func(filter QueryFilter) []Entity {
+		var entities []Entity
+		NewQuery1[testPosition](registry, filter).Each(func(entity Entity, _ testPosition) {
+			entities = append(entities, entity)
+		})
+		slices.SortFunc(entities, func(a, b Entity) int { return int(a.Index()) - int(b.Index()) })
+		return entities
+	}
+	if got, want := matched(RelatedTo[testTargets](enemy)), []Entity{first}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RelatedTo(enemy) matched %v, want %v", got, want)
+	}
+	if got, want := matched(RelatedTo[testTargets](NilEntity)), []Entity{first, second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RelatedTo(NilEntity) matched %v, want %v", got, want)
+	}
+
+	Unrelate[testTargets](registry, first, enemy)
+	if got := matched(RelatedTo[testTargets](enemy)); len(got) != 0 {
+		t.Errorf("RelatedTo(enemy) after Unrelate matched %v, want none", got)
+	}
+}
@@ -0,0 +1,58 @@
+package core
+
+// InsertResource stores a resource of type T on the registry, replacing any
+// resource of the same type. Resources are singletons that do not belong to an
+// entity, such as the frame time or an asset cache. Systems that read or write a
+// resource declare it with Reads or Writes like a component type.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to store the resource on.
+//	resource (T): The resource to store.
+func InsertResource[T any](registry *Registry, resource T) {
+	registry.resourcesMutex.Lock()
+	registry.resources[componentType[T]()] = resource
+	registry.resourcesMutex.Unlock()
+}
+
+// Resource retrieves the resource of type T. Store pointers to mutate a resource
+// in place.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the resource.
+//
+// Returns:
+//
+//	T: The resource, or the zero value of T if none was inserted.
+//	bool: True if a resource of type T was inserted.
+func Resource[T any](registry *Registry) (T, bool) {
+	registry.resourcesMutex.RLock()
+	resource, ok := registry.resources[componentType[T]()]
+	registry.resourcesMutex.RUnlock()
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return resource.(T), true
+}
+
+// RemoveResource removes the resource of type T.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the resource.
+//
+// Returns:
+//
+//	bool: True if a resource of type T was inserted.
+func RemoveResource[T any](registry *Registry) bool {
+	identifier := componentType[T]()
+	registry.resourcesMutex.Lock()
+	defer registry.resourcesMutex.Unlock()
+	if _, ok := registry.resources[identifier]; !ok {
+		return false
+	}
+	delete(registry.resources, identifier)
+	return true
+}
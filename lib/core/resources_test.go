@@ -0,0 +1,35 @@
+package core
+
+import "testing"
+
+// TestResourceLifecycle checks inserting, replacing, reading and removing a
+// resource, and that resources of other types are kept apart.
+func TestResourceLifecycle(t *testing.T) {
+	registry := NewRegistry()
+	if clock, ok := Resource[*testClock](registry); ok || clock != nil {
+		t.Fatalf("Resource() before insert = %v, %v, want nil, false", clock, ok)
+	}
+
+	InsertResource(registry, &testClock{Frame: 1})
+	InsertResource(registry, testClock{Frame: 5})
+	InsertResource(registry, &testClock{Frame: 2})
+	if clock, ok := Resource[*testClock](registry); !ok || clock.Frame != 2 {
+		t.Fatalf("Resource() after replace = %v, %v, want Frame 2, true", clock, ok)
+	}
+	if clock, ok := Resource[testClock](registry); !ok || clock.Frame != 5 {
+		t.Errorf("Resource[testClock]() = %v, %v, want Frame 5, true", clock, ok)
+	}
+
+	if !RemoveResource[*testClock](registry) {
+		t.Fatal("RemoveResource() = false, want true")
+	}
+	if RemoveResource[*testClock](registry) {
+		t.Error("RemoveResource() twice = true, want false")
+	}
+	if clock, ok := Resource[*testClock](registry); ok || clock != nil {
+		t.Errorf("Resource() after remove = %v, %v, want nil, false", clock, ok)
+	}
+	if _, ok := Resource[testClock](registry); !ok {
+		t.Error("RemoveResource[*testClock]() removed the testClock resource")
+	}
+}
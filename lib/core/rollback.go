@@ -0,0 +1,437 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Cloner is implemented by components and resources that need a deep copy to be
+// captured in a Snapshot. Other values are copied shallowly: values are copied as
+// they are and pointers to a copy of what they point to, so slices and maps they
+// contain are shared with the live registry.
+type Cloner interface {
+	Clone() Component
+}
+
+// Snapshot is the state of every entity, component, resource and relationship of
+// a registry at one point in time. Components that did not change since the
+// snapshot a new one was based on are shared between both snapshots instead of
+// being copied again, so keeping the snapshots of the last few frames costs little
+// more than one copy of the world.
+type Snapshot struct {
+	registry    *Registry
+	tick        Tick
+	generations []uint32
+	freeIndices []uint32
+	nextIndex   uint32
+	states      []*entityState
+	resources   map[reflect.Type]interface{}
+	relations   map[reflect.Type]*relationIndex
+}
+
+// entityState holds the captured components of an entity. The values of table
+// components and the ticks at which they were added are kept in the column order
+// of the archetype of the entity.
+type entityState struct {
+	entity    Entity
+	archetype *archetype
+	values    []Component
+	added     []Tick
+	sparse    []capturedComponent
+}
+
+// capturedComponent is a captured sparse-set component together with its type
+// and the tick at which it was added.
+type capturedComponent struct {
+	info      *componentInfo
+	component Component
+	added     Tick
+}
+
+// Snapshot captures the state of the registry, e.g. at the end of a tick. It must
+// not be called while systems are running.
+//
+// To stay cheap, capturing relies on change ticks rather than comparing values:
+// a component whose change tick is older than the base snapshot is shared without
+// being inspected, and one changed after it is copied. Components mutated in place
+// must therefore be marked with MarkChanged to be captured again. Only components
+// changed at the very tick of the base snapshot, which may have happened before or
+// after it was taken, and resources are compared with reflect.DeepEqual.
+//
+// Parameters:
+//
+//	base (*Snapshot): A previous snapshot of the registry whose unchanged values are shared, or nil.
+//
+// Returns:
+//
+//	*Snapshot: The captured state.
+func (r *Registry) Snapshot(base *Snapshot) *Snapshot {
+	if base != nil && base.registry != r {
+		base = nil
+	}
+
+	r.allocator.mutex.Lock()
+	snapshot := &Snapshot{
+		registry:    r,
+		tick:        r.tick,
+		generations: append([]uint32(nil), r.generations...),
+		freeIndices: append([]uint32(nil), r.allocator.freeIndices...),
+		nextIndex:   r.allocator.nextIndex,
+		states:      make([]*entityState, len(r.records)),
+		resources:   make(map[reflect.Type]interface{}, len(r.resources)),
+		relations:   make(map[reflect.Type]*relationIndex, len(r.relations)),
+	}
+	r.allocator.mutex.Unlock()
+
+	for index, record := range r.records {
+		if record.archetype == nil {
+			continue
+		}
+		entity := newEntity(uint32(index), r.generations[index])
+		var previous *entityState
+		if base != nil && index < len(base.states) && base.states[index] != nil && base.states[index].entity == entity {
+			previous = base.states[index]
+		}
+		snapshot.states[index] = r.captureEntity(entity, record, previous, base)
+	}
+
+	r.resourcesMutex.RLock()
+	for identifier, resource := range r.resources {
+		if base != nil {
+			if captured, ok := base.resources[identifier]; ok && reflect.DeepEqual(resource, captured) {
+				snapshot.resources[identifier] = captured
+				continue
+			}
+		}
+		snapshot.resources[identifier] = cloneValue(resource)
+	}
+	r.resourcesMutex.RUnlock()
+
+	for identifier, index := range r.relations {
+		snapshot.relations[identifier] = index.clone()
+	}
+	return snapshot
+}
+
+// Restore returns the registry to the state captured in a snapshot. Entities keep
+// the identifiers they had when the snapshot was taken. The restored components
+// keep the ticks at which they were added, so Added does not match them again,
+// and are marked as changed. Hooks do not run. Restore must not be called
+// while systems are running or commands are pending.
+//
+// Restore cannot bring back what remove hooks released outside the registry.
+// Without a Cloner, a restored component shares the maps, slices and pointers it
+// holds with the component that was removed, so e.g. images deallocated by an
+// OnRemove hook stay deallocated after rolling back past the removal. Such
+// components either implement Cloner with a copy that owns its resources, or
+// release the resources once no snapshot refers to them anymore.
+//
+// Parameters:
+//
+//	snapshot (*Snapshot): The state to restore.
+//
+// Returns:
+//
+//	error: An error if the snapshot was taken of another registry.
+func (r *Registry) Restore(snapshot *Snapshot) error {
+	if snapshot == nil || snapshot.registry != r {
+		return errors.New("core: cannot restore a snapshot of another registry")
+	}
+
+	for _, arch := range r.archetypes {
+		arch.entities = arch.entities[:0]
+		for idx, col := range arch.columns {
+			arch.columns[idx] = col.newEmpty()
+			arch.ticks[idx] = newComponentTicks(arch.components[idx])
+		}
+	}
+	for _, info := range r.sparseInfos {
+		info.sparse = newSparseSet(info.sparse.data.newEmpty())
+	}
+
+	r.generations = append(r.generations[:0], snapshot.generations...)
+	r.records = make([]entityRecord, len(snapshot.states))
+	r.allocator.mutex.Lock()
+	r.allocator.freeIndices = append(r.allocator.freeIndices[:0], snapshot.freeIndices...)
+	r.allocator.nextIndex = snapshot.nextIndex
+	r.allocator.mutex.Unlock()
+	clear(r.destroying)
+
+	for index, state := range snapshot.states {
+		if state == nil {
+			continue
+		}
+		arch := state.archetype
+		arch.entities = append(arch.entities, state.entity)
+		for idx, value := range state.values {
+			arch.columns[idx].push(cloneValue(value))
+			arch.ticks[idx].pushTicks(state.added[idx], r.tick)
+		}
+		r.records[index] = entityRecord{archetype: arch, row: len(arch.entities) - 1}
+		for _, captured := range state.sparse {
+			sparse := captured.info.sparse
+			sparse.insert(state.entity, cloneValue(captured.component), r.tick)
+			row, _ := sparse.row(state.entity)
+			sparse.ticks.added[row] = captured.added
+		}
+	}
+
+	r.resourcesMutex.Lock()
+	r.resources = make(map[reflect.Type]interface{}, len(snapshot.resources))
+	for identifier, resource := range snapshot.resources {
+		r.resources[identifier] = cloneValue(resource)
+	}
+	r.resourcesMutex.Unlock()
+
+	r.relations = make(map[reflect.Type]*relationIndex, len(snapshot.relations))
+	for identifier, index := range snapshot.relations {
+		r.relations[identifier] = index.clone()
+	}
+	return nil
+}
+
+// captureEntity captures the components of an entity, sharing the values that
+// did not change since its previous state was captured.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to capture.
+//	record (entityRecord): The archetype and row of the entity.
+//	previous (*entityState): The state of the entity in the base snapshot, or nil.
+//	base (*Snapshot): The base snapshot, or nil.
+//
+// Returns:
+//
+//	*entityState: The captured state, which is previous itself if nothing changed.
+func (r *Registry) captureEntity(entity Entity, record entityRecord, previous *entityState, base *Snapshot) *entityState {
+	arch := record.archetype
+	if previous != nil && previous.archetype != arch {
+		previous = nil
+	}
+	if previous != nil && r.unchangedSince(record, previous, base.tick) {
+		return previous
+	}
+
+	state := &entityState{
+		entity:    entity,
+		archetype: arch,
+		values:    make([]Component, len(arch.columns)),
+		added:     make([]Tick, len(arch.columns)),
+	}
+	for idx, col := range arch.columns {
+		ticks := arch.ticks[idx]
+		current := col.get(record.row)
+		if previous != nil && unchanged(current, ticks, record.row, previous.values[idx], base.tick) {
+			state.values[idx] = previous.values[idx]
+		} else {
+			state.values[idx] = cloneValue(current)
+		}
+		if ticks != nil {
+			state.added[idx] = ticks.added[record.row]
+		}
+	}
+	for _, info := range r.sparseInfos {
+		row, ok := info.sparse.row(entity)
+		if !ok {
+			continue
+		}
+		current := info.sparse.data.get(row)
+		if value, ok := previous.sparseValue(info); ok && unchanged(current, &info.sparse.ticks, row, value, base.tick) {
+			current = value
+		} else {
+			current = cloneValue(current)
+		}
+		state.sparse = append(state.sparse, capturedComponent{info: info, component: current, added: info.sparse.ticks.added[row]})
+	}
+	return state
+}
+
+// unchangedSince reports whether no component of an entity changed since a
+// previous state of the same archetype was captured.
+//
+// Parameters:
+//
+//	record (entityRecord): The archetype and row of the entity.
+//	previous (*entityState): The previous state of the entity.
+//	since (Tick): The tick at which the previous state was captured.
+//
+// Returns:
+//
+//	bool: True if the previous state can be shared.
+func (r *Registry) unchangedSince(record entityRecord, previous *entityState, since Tick) bool {
+	for idx, col := range record.archetype.columns {
+		if !unchanged(col.get(record.row), record.archetype.ticks[idx], record.row, previous.values[idx], since) {
+			return false
+		}
+	}
+	count := 0
+	for _, info := range r.sparseInfos {
+		row, ok := info.sparse.row(previous.entity)
+		if !ok {
+			continue
+		}
+		count++
+		if value, ok := previous.sparseValue(info); !ok || !unchanged(info.sparse.data.get(row), &info.sparse.ticks, row, value, since) {
+			return false
+		}
+	}
+	return count == len(previous.sparse)
+}
+
+// unchanged reports whether a component still equals its value captured at a
+// given tick. The change tick of the component decides, unless the component
+// changed at that very tick, in which case the values are compared.
+//
+// Parameters:
+//
+//	current (Component): The component.
+//	ticks (*componentTicks): The change ticks of its column, nil for tags.
+//	row (int): The row of the component.
+//	captured (Component): The captured value.
+//	since (Tick): The tick at which the value was captured.
+//
+// Returns:
+//
+//	bool: True if the captured value can be shared.
+func unchanged(current Component, ticks *componentTicks, row int, captured Component, since Tick) bool {
+	if ticks == nil {
+		return true
+	}
+	if changed := ticks.changed[row]; changed != since {
+		return changed < since
+	}
+	return reflect.DeepEqual(current, captured)
+}
+
+// sparseValue returns the captured value of a sparse-set component.
+//
+// Parameters:
+//
+//	info (*componentInfo): The component type.
+//
+// Returns:
+//
+//	Component: The captured value.
+//	bool: True if the state contains a value of the type.
+func (s *entityState) sparseValue(info *componentInfo) (Component, bool) {
+	if s == nil {
+		return nil, false
+	}
+	for _, captured := range s.sparse {
+		if captured.info == info {
+			return captured.component, true
+		}
+	}
+	return nil, false
+}
+
+// clone copies the index, so that later changes to either copy do not affect
+// the other.
+//
+// Returns:
+//
+//	*relationIndex: The copy.
+func (i *relationIndex) clone() *relationIndex {
+	copied := &relationIndex{
+		targets: make(map[Entity][]Entity, len(i.targets)),
+		sources: make(map[Entity][]Entity, len(i.sources)),
+	}
+	for source, targets := range i.targets {
+		copied.targets[source] = append([]Entity(nil), targets...)
+	}
+	for target, sources := range i.sources {
+		copied.sources[target] = append([]Entity(nil), sources...)
+	}
+	return copied
+}
+
+// cloneValue copies a component or resource as described by Cloner.
+//
+// Parameters:
+//
+//	value (interface{}): The value to copy.
+//
+// Returns:
+//
+//	interface{}: The copy.
+func cloneValue(value interface{}) interface{} {
+	if cloner, ok := value.(Cloner); ok {
+		return cloner.Clone()
+	}
+	original := reflect.ValueOf(value)
+	if original.Kind() != reflect.Pointer || original.IsNil() {
+		return value
+	}
+	copied := reflect.New(original.Elem().Type())
+	copied.Elem().Set(original.Elem())
+	return copied.Interface()
+}
+
+// SnapshotHistory keeps the snapshots of the most recent frames for rollback.
+// Every snapshot is based on the one before, so unchanged components are shared.
+type SnapshotHistory struct {
+	capacity  int
+	frames    []uint64
+	snapshots []*Snapshot
+}
+
+// NewSnapshotHistory creates a history keeping the snapshots of up to capacity frames.
+//
+// Parameters:
+//
+//	capacity (int): The number of frames to keep, at least 1.
+//
+// Returns:
+//
+//	*SnapshotHistory: The newly created history.
+func NewSnapshotHistory(capacity int) *SnapshotHistory {
+	return &SnapshotHistory{capacity: max(capacity, 1)}
+}
+
+// Capture takes a snapshot of the registry for a frame, dropping the oldest
+// snapshot once the history is full.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to capture.
+//	frame (uint64): The frame the snapshot belongs to, increasing with every capture.
+func (h *SnapshotHistory) Capture(registry *Registry, frame uint64) {
+	var base *Snapshot
+	if len(h.snapshots) > 0 {
+		base = h.snapshots[len(h.snapshots)-1]
+	}
+	h.frames = append(h.frames, frame)
+	h.snapshots = append(h.snapshots, registry.Snapshot(base))
+	if len(h.snapshots) > h.capacity {
+		h.frames[0], h.snapshots[0] = 0, nil
+		h.frames, h.snapshots = h.frames[1:], h.snapshots[1:]
+	}
+}
+
+// Rollback restores the registry to the snapshot of a frame and forgets the
+// snapshots of later frames, which are captured again when the frames are
+// simulated anew.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry to restore.
+//	frame (uint64): The frame to return to.
+//
+// Returns:
+//
+//	error: An error if no snapshot of the frame is kept.
+func (h *SnapshotHistory) Rollback(registry *Registry, frame uint64) error {
+	for idx, captured := range h.frames {
+		if captured != frame {
+			continue
+		}
+		if err := registry.Restore(h.snapshots[idx]); err != nil {
+			return err
+		}
+		clear(h.snapshots[idx+1:])
+		h.frames, h.snapshots = h.frames[:idx+1], h.snapshots[:idx+1]
+		return nil
+	}
+	return fmt.Errorf("core: no snapshot of frame %d", frame)
+}
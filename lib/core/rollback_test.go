@@ -0,0 +1,218 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testInventory is a component with a slice, deep-copied through Cloner.
+type testInventory struct {
+	Items []string
+}
+
+// Clone returns a copy of the inventory owning its own items.
+func (i *testInventory) Clone() Component {
+	return &testInventory{Items: append([]string(nil), i.Items...)}
+}
+
+// TestSnapshotSharesUnchangedStates checks that snapshots based on a previous one
+// share the states of unchanged entities and the values of unchanged components.
+func TestSnapshotSharesUnchangedStates(t *testing.T) {
+	registry := NewRegistry()
+	still, moving := registry.NewEntity(), registry.NewEntity()
+	Add(registry, still, &testPosition{X: 1})
+	Add(registry, moving, &testPosition{X: 2})
+	Add(registry, moving, &testVelocity{DX: 1})
+
+	first := registry.Snapshot(nil)
+	position, _ := Get[*testPosition](registry, moving)
+	position.X++
+	second := registry.Snapshot(first)
+
+	if second.states[still.Index()] != first.states[still.Index()] {
+		t.Error("the state of the unchanged entity was not shared")
+	}
+	before, after := first.states[moving.Index()], second.states[moving.Index()]
+	if before == after {
+		t.Fatal("the state of the changed entity was shared")
+	}
+	for idx := range after.values {
+		shared := after.values[idx] == before.values[idx]
+		if _, isPosition := after.values[idx].(*testPosition); shared == isPosition {
+			t.Errorf("value %d shared = %t, want only the unchanged velocity shared", idx, shared)
+		}
+	}
+	positionIdx := after.archetype.indices[registry.componentInfo(componentType[*testPosition]()).id]
+	if got := before.values[positionIdx].(*testPosition).X; got != 2 {
+		t.Errorf("first snapshot position = %v, want 2", got)
+	}
+}
+
+// TestRestoreDestroyedEntities checks that restoring brings back destroyed
+// entities with their identifiers, table, tag and sparse-set components,
+// resources and relationships, and drops entities created afterwards.
+func TestRestoreDestroyedEntities(t *testing.T) {
+	registry := NewRegistry()
+	RegisterComponent[*testInventory](registry, StorageSparseSet)
+	owner, other := registry.NewEntity(), registry.NewEntity()
+	Add(registry, owner, testPosition{X: 1})
+	AddTag[testTag](registry, owner)
+	Add(registry, owner, &testInventory{Items: []string{"sword"}})
+	Relate[testLikes](registry, owner, other)
+	InsertResource(registry, &testClock{Frame: 1})
+
+	snapshot := registry.Snapshot(nil)
+	inventory, _ := Get[*testInventory](registry, owner)
+	inventory.Items[0] = "stick"
+	registry.DestroyEntity(owner)
+	created := registry.NewEntity()
+	clock, _ := Resource[*testClock](registry)
+	clock.Frame = 2
+
+	if err := registry.Restore(snapshot); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if !registry.IsAlive(owner) || registry.IsAlive(created) {
+		t.Fatalf("alive after Restore(): owner %t, created %t, want true and false", registry.IsAlive(owner), registry.IsAlive(created))
+	}
+	if position, _ := Get[testPosition](registry, owner); position.X != 1 {
+		t.Errorf("position = %v, want X 1", position)
+	}
+	if !Has[testTag](registry, owner) {
+		t.Error("tag was not restored")
+	}
+	if inventory, _ := Get[*testInventory](registry, owner); !reflect.DeepEqual(inventory.Items, []string{"sword"}) {
+		t.Errorf("inventory = %v, want [sword]", inventory.Items)
+	}
+	if !IsRelated[testLikes](registry, owner, other) {
+		t.Error("relationship was not restored")
+	}
+	if clock, _ := Resource[*testClock](registry); clock.Frame != 1 {
+		t.Errorf("clock = %v, want frame 1", clock)
+	}
+	if next := registry.NewEntity(); next != newEntity(2, 1) {
+		t.Errorf("NewEntity() after Restore() = %v, want %v from the allocator state of the snapshot", next, newEntity(2, 1))
+	}
+
+	if err := NewRegistry().Restore(snapshot); err == nil {
+		t.Error("Restore() of another registry's snapshot error = nil, want an error")
+	}
+}
+
+// TestRollbackTrimsLaterFrames checks that rolling back restores the state of a
+// frame and forgets the snapshots of the frames after it.
+func TestRollbackTrimsLaterFrames(t *testing.T) {
+	registry := NewRegistry()
+	entity := registry.NewEntity()
+	Add(registry, entity, testPosition{})
+	history := NewSnapshotHistory(3)
+	for frame := uint64(1); frame <= 4; frame++ {
+		Add(registry, entity, testPosition{X: float64(frame)})
+		history.Capture(registry, frame)
+	}
+
+	if err := history.Rollback(registry, 1); err == nil {
+		t.Error("Rollback() to a dropped frame error = nil, want an error")
+	}
+	if err := history.Rollback(registry, 3); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if position, _ := Get[testPosition](registry, entity); position.X != 3 {
+		t.Errorf("position = %v, want X 3", position)
+	}
+	if !reflect.DeepEqual(history.frames, []uint64{2, 3}) {
+		t.Errorf("kept frames = %v, want [2 3]", history.frames)
+	}
+	if err := history.Rollback(registry, 4); err == nil {
+		t.Error("Rollback() to a trimmed frame error = nil, want an error")
+	}
+
+	Add(registry, entity, testPosition{X: 40})
+	history.Capture(registry, 4)
+	if err := history.Rollback(registry, 4); err != nil {
+		t.Fatalf("Rollback() to the resimulated frame error = %v", err)
+	}
+	if position, _ := Get[testPosition](registry, entity); position.X != 40 {
+		t.Errorf("position = %v, want X 40", position)
+	}
+}
+
+// TestSnapshotTrustsChangeTicks checks that a snapshot copies the components
+// marked as changed after its base and shares the others without comparing them,
+// even if they were mutated in place without MarkChanged.
+func TestSnapshotTrustsChangeTicks(t *testing.T) {
+	registry := NewRegistry()
+	marked, unmarked := registry.NewEntity(), registry.NewEntity()
+	Add(registry, marked, &testPosition{X: 1})
+	Add(registry, unmarked, &testPosition{X: 1})
+	var mutate func()
+	registry.AddSystem("mutate", funcSystem(func(registry *Registry, commands *Commands) {
+		if mutate != nil {
+			mutate()
+		}
+	}))
+	runFrame(t, registry)
+	first := registry.Snapshot(nil)
+
+	mutate = func() {
+		for _, entity := range []Entity{marked, unmarked} {
+			position, _ := Get[*testPosition](registry, entity)
+			position.X = 2
+		}
+		MarkChanged[*testPosition](registry, marked)
+	}
+	runFrame(t, registry)
+	second := registry.Snapshot(first)
+
+	if second.states[marked.Index()] == first.states[marked.Index()] {
+		t.Error("the state of the marked entity was shared")
+	}
+	if got := second.states[marked.Index()].values[0].(*testPosition).X; got != 2 {
+		t.Errorf("captured position of the marked entity = %v, want 2", got)
+	}
+	if second.states[unmarked.Index()] != first.states[unmarked.Index()] {
+		t.Error("the state of the unmarked entity was compared instead of shared")
+	}
+}
+
+// TestRestoreKeepsAddedTicks checks that a system sees restored components as
+// changed but not as added, so that rolling back does not repeat the logic run on
+// adding them.
+func TestRestoreKeepsAddedTicks(t *testing.T) {
+	registry := NewRegistry()
+	RegisterComponent[*testInventory](registry, StorageSparseSet)
+	entity := registry.NewEntity()
+	Add(registry, entity, testPosition{})
+	Add(registry, entity, &testInventory{})
+
+	var added, changed int
+	registry.AddSystem("observer", funcSystem(func(registry *Registry, commands *Commands) {
+		added, changed = 0, 0
+		count := func(filter QueryFilter) int {
+			matched := 0
+			NewQuery2[testPosition, *testInventory](registry, filter).Each(func(Entity, testPosition, *testInventory) {
+				matched++
+			})
+			return matched
+		}
+		added = count(Added[testPosition](commands.LastRun())) + count(Added[*testInventory](commands.LastRun()))
+		changed = count(Changed[testPosition](commands.LastRun())) + count(Changed[*testInventory](commands.LastRun()))
+	}))
+	runFrame(t, registry)
+	snapshot := registry.Snapshot(nil)
+	runFrame(t, registry)
+	if added != 0 || changed != 0 {
+		t.Fatalf("before Restore() added %d, changed %d, want 0 and 0", added, changed)
+	}
+
+	if err := registry.Restore(snapshot); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	runFrame(t, registry)
+	if added != 0 {
+		t.Errorf("restored components matched Added %d times, want 0", added)
+	}
+	if changed != 2 {
+		t.Errorf("restored components matched Changed %d times, want 2", changed)
+	}
+}
@@ -0,0 +1,213 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SystemOption configures how a system is scheduled relative to other systems.
+type SystemOption func(entry *systemEntry)
+
+// WithPriority sets the priority of a system. Among systems whose dependencies are
+// satisfied, systems with a higher priority run first. Systems with equal priority
+// run in registration order. The default priority is 0.
+//
+// Parameters:
+//
+//	priority (int): The priority of the system.
+//
+// Returns:
+//
+//	SystemOption: The option applying the priority.
+func WithPriority(priority int) SystemOption {
+	return func(entry *systemEntry) {
+		entry.priority = priority
+	}
+}
+
+// Before declares that a system must run before the system with the given label.
+//
+// Parameters:
+//
+//	label (string): The label of the system that must run later.
+//
+// Returns:
+//
+//	SystemOption: The option declaring the dependency.
+func Before(label string) SystemOption {
+	return func(entry *systemEntry) {
+		entry.before = append(entry.before, label)
+	}
+}
+
+// After declares that a system must run after the system with the given label.
+//
+// Parameters:
+//
+//	label (string): The label of the system that must run earlier.
+//
+// Returns:
+//
+//	SystemOption: The option declaring the dependency.
+func After(label string) SystemOption {
+	return func(entry *systemEntry) {
+		entry.after = append(entry.after, label)
+	}
+}
+
+// systemEntry holds a registered system together with its scheduling constraints.
+// Exactly one of system and renderer is set.
+type systemEntry struct {
+	label    string
+	system   System
+	renderer Renderer
+	phase    Phase
+	priority int
+	sequence int
+	disabled bool
+	commands *Commands
+	before   []string
+	after    []string
+	declared bool
+	reads    []reflect.Type
+	writes   []reflect.Type
+}
+
+// scheduler orders the systems of a single phase by their declared dependencies,
+// priorities and registration order, and groups them into stages of systems that
+// may run concurrently.
+type scheduler struct {
+	entries      []*systemEntry
+	order        []*systemEntry
+	stages       [][]*systemEntry
+	nextSequence int
+	dirty        bool
+}
+
+// add registers a system entry.
+//
+// Parameters:
+//
+//	entry (*systemEntry): The entry to register.
+func (s *scheduler) add(entry *systemEntry) {
+	entry.sequence = s.nextSequence
+	s.nextSequence++
+	s.entries = append(s.entries, entry)
+	s.dirty = true
+}
+
+// remove unregisters the entry with the given label.
+//
+// Parameters:
+//
+//	label (string): The label of the system.
+//
+// Returns:
+//
+//	bool: True if an entry with the label was registered.
+func (s *scheduler) remove(label string) bool {
+	for idx, entry := range s.entries {
+		if entry.label == label {
+			s.entries = append(s.entries[:idx], s.entries[idx+1:]...)
+			s.dirty = true
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the entry registered with the given label.
+//
+// Parameters:
+//
+//	label (string): The label of the system.
+//
+// Returns:
+//
+//	*systemEntry: The entry, or nil if no system has that label.
+func (s *scheduler) find(label string) *systemEntry {
+	for _, entry := range s.entries {
+		if entry.label == label {
+			return entry
+		}
+	}
+	return nil
+}
+
+// schedule returns the entries in execution order, rebuilding the order if systems
+// were added since the last call.
+//
+// Returns:
+//
+//	[]*systemEntry: The entries in execution order.
+//	error: An error if a dependency is unregistered or the dependencies form a cycle.
+func (s *scheduler) schedule() ([]*systemEntry, error) {
+	if !s.dirty {
+		return s.order, nil
+	}
+
+	successors := make(map[*systemEntry][]*systemEntry, len(s.entries))
+	pending := make(map[*systemEntry]int, len(s.entries))
+	for _, entry := range s.entries {
+		for _, label := range entry.before {
+			other := s.find(label)
+			if other == nil {
+				return nil, fmt.Errorf("core: system %q must run before unregistered system %q", entry.label, label)
+			}
+			successors[entry] = append(successors[entry], other)
+			pending[other]++
+		}
+		for _, label := range entry.after {
+			other := s.find(label)
+			if other == nil {
+				return nil, fmt.Errorf("core: system %q must run after unregistered system %q", entry.label, label)
+			}
+			successors[other] = append(successors[other], entry)
+			pending[entry]++
+		}
+	}
+
+	var ready []*systemEntry
+	for _, entry := range s.entries {
+		if pending[entry] == 0 {
+			ready = append(ready, entry)
+		}
+	}
+
+	order := make([]*systemEntry, 0, len(s.entries))
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(i, j int) bool {
+			if ready[i].priority != ready[j].priority {
+				return ready[i].priority > ready[j].priority
+			}
+			return ready[i].sequence < ready[j].sequence
+		})
+		next := ready[0]
+		ready = ready[1:]
+		order = append(order, next)
+		for _, successor := range successors[next] {
+			pending[successor]--
+			if pending[successor] == 0 {
+				ready = append(ready, successor)
+			}
+		}
+	}
+
+	if len(order) < len(s.entries) {
+		var cyclic []string
+		for _, entry := range s.entries {
+			if pending[entry] > 0 {
+				cyclic = append(cyclic, strconv.Quote(entry.label))
+			}
+		}
+		return nil, fmt.Errorf("core: dependency cycle between systems %s", strings.Join(cyclic, ", "))
+	}
+
+	s.order = order
+	s.stages = buildStages(order)
+	s.dirty = false
+	return s.order, nil
+}
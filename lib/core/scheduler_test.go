@@ -0,0 +1,124 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// logSystem appends its label to a shared log whenever it is updated.
+type logSystem struct {
+	label string
+	log   *[]string
+}
+
+// Update appends the label of the system to the log.
+func (ls *logSystem) Update(registry *Registry, commands *Commands) {
+	*ls.log = append(*ls.log, ls.label)
+}
+
+// runOrder registers a logSystem per label with the given options, runs
+// PhaseUpdate once and returns the labels in the order the systems ran.
+func runOrder(t *testing.T, systems []string, options map[string][]SystemOption) []string {
+	t.Helper()
+	registry := NewRegistry()
+	var log []string
+	for _, label := range systems {
+		if err := registry.AddSystem(label, &logSystem{label: label, log: &log}, options[label]...); err != nil {
+			t.Fatalf("AddSystem(%q) error = %v", label, err)
+		}
+	}
+	if err := registry.RunPhase(PhaseUpdate); err != nil {
+		t.Fatalf("RunPhase() error = %v", err)
+	}
+	return log
+}
+
+// TestSchedulePriorityAndRegistrationOrder checks that higher priorities run
+// first and that equal priorities keep their registration order.
+func TestSchedulePriorityAndRegistrationOrder(t *testing.T) {
+	got := runOrder(t, []string{"a", "b", "c", "d"}, map[string][]SystemOption{
+		"b": {WithPriority(-1)},
+		"c": {WithPriority(5)},
+		"d": {WithPriority(5)},
+	})
+	if want := []string{"c", "d", "a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+// TestScheduleDependenciesOverridePriority checks that Before and After hold
+// regardless of priority.
+func TestScheduleDependenciesOverridePriority(t *testing.T) {
+	got := runOrder(t, []string{"input", "movement", "render"}, map[string][]SystemOption{
+		"input":    {Before("movement")},
+		"movement": {WithPriority(10)},
+		"render":   {After("movement"), WithPriority(20)},
+	})
+	if want := []string{"input", "movement", "render"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+// TestScheduleErrors checks the errors reported for unregistered dependencies and
+// cycles, and that no system runs while the schedule is invalid.
+func TestScheduleErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options map[string][]SystemOption
+		want    string
+	}{
+		{
+			name:    "before unregistered",
+			options: map[string][]SystemOption{"a": {Before("missing")}},
+			want:    `core: system "a" must run before unregistered system "missing" in phase Update`,
+		},
+		{
+			name:    "after unregistered",
+			options: map[string][]SystemOption{"b": {After("missing")}},
+			want:    `core: system "b" must run after unregistered system "missing" in phase Update`,
+		},
+		{
+			name: "cycle",
+			options: map[string][]SystemOption{
+				"a": {Before("b")},
+				"b": {Before("c")},
+				"c": {Before("a")},
+			},
+			want: `core: dependency cycle between systems "a", "b", "c" in phase Update`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := NewRegistry()
+			var log []string
+			for _, label := range []string{"a", "b", "c"} {
+				if err := registry.AddSystem(label, &logSystem{label: label, log: &log}, test.options[label]...); err != nil {
+					t.Fatalf("AddSystem(%q) error = %v", label, err)
+				}
+			}
+			err := registry.BuildSchedule()
+			if err == nil || err.Error() != test.want {
+				t.Fatalf("BuildSchedule() error = %v, want %s", err, test.want)
+			}
+			if err := registry.RunPhase(PhaseUpdate); err == nil {
+				t.Error("RunPhase() error = nil, want the schedule error")
+			}
+			if len(log) != 0 {
+				t.Errorf("systems ran despite the schedule error: %v", log)
+			}
+		})
+	}
+}
+
+// TestScheduleRebuildsAfterRemoval checks that removing a system invalidates the
+// dependencies on it.
+func TestScheduleRebuildsAfterRemoval(t *testing.T) {
+	registry := NewRegistry()
+	var log []string
+	registry.AddSystem("a", &logSystem{label: "a", log: &log}, After("b"))
+	registry.AddSystem("b", &logSystem{label: "b", log: &log})
+	registry.RemoveSystem("b")
+	if err := registry.BuildSchedule(); err == nil {
+		t.Fatal("BuildSchedule() error = nil, want an error for the removed dependency")
+	}
+}
@@ -0,0 +1,575 @@
+package core
+
+import (
+	"cmp"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// binaryMagic starts every binary save.
+const binaryMagic = "KECS\x01"
+
+// serializableType is a component, resource or relation type known by name.
+type serializableType struct {
+	name       string
+	identifier reflect.Type
+	newColumn  func() column
+}
+
+// SerializableTypes names the component, resource and relation types that take
+// part in saving and loading a registry. Values are encoded with encoding/json
+// for JSON saves and encoding/gob for binary saves, so types customize
+// their encoding by implementing the interfaces of those packages. Components and
+// resources of types that were not registered are skipped when saving. Parent and
+// Children are registered by default.
+type SerializableTypes struct {
+	byName map[string]*serializableType
+	byType map[reflect.Type]*serializableType
+}
+
+// NewSerializableTypes creates a set of serializable types containing Parent and Children.
+//
+// Returns:
+//
+//	*SerializableTypes: The newly created set of types.
+func NewSerializableTypes() *SerializableTypes {
+	types := &SerializableTypes{
+		byName: make(map[string]*serializableType),
+		byType: make(map[reflect.Type]*serializableType),
+	}
+	_ = RegisterSerializable[Parent](types, "core.Parent")
+	_ = RegisterSerializable[Children](types, "core.Children")
+	return types
+}
+
+// RegisterSerializable registers the type T under a name. The name is written to
+// saves instead of the Go type, so it must stay the same across versions of
+// the game for saves to remain loadable.
+//
+// Parameters:
+//
+//	types (*SerializableTypes): The set of types to register with.
+//	name (string): The stable name of the type.
+//
+// Returns:
+//
+//	error: An error if the name or the type is already registered.
+func RegisterSerializable[T any](types *SerializableTypes, name string) error {
+	identifier := componentType[T]()
+	if _, ok := types.byName[name]; ok {
+		return fmt.Errorf("core: serializable type name %q is already registered", name)
+	}
+	if existing, ok := types.byType[identifier]; ok {
+		return fmt.Errorf("core: type %v is already registered as %q", identifier, existing.name)
+	}
+	entry := &serializableType{name: name, identifier: identifier, newColumn: newTypedColumn[T]}
+	types.byName[name] = entry
+	types.byType[identifier] = entry
+	return nil
+}
+
+// lookup returns the registered type with the given name.
+//
+// Parameters:
+//
+//	name (string): The name of the type.
+//
+// Returns:
+//
+//	*serializableType: The registered type.
+//	error: An error if no type is registered under the name.
+func (t *SerializableTypes) lookup(name string) (*serializableType, error) {
+	entry, ok := t.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("core: unknown serializable type %q", name)
+	}
+	return entry, nil
+}
+
+// savedValue is a component, resource or relation of a registered type.
+type savedValue struct {
+	entry *serializableType
+	value interface{}
+}
+
+// savedEntity is an entity with the components of registered types.
+type savedEntity struct {
+	entity     Entity
+	components []savedValue
+}
+
+// savedRelation is a relationship pair of a registered relation kind.
+type savedRelation struct {
+	entry  *serializableType
+	source Entity
+	target Entity
+}
+
+// savedWorld is the format-independent content of a saved registry.
+type savedWorld struct {
+	entities  []savedEntity
+	resources []savedValue
+	relations []savedRelation
+}
+
+// save collects the entities, components, resources and relationships of the
+// registry whose types are registered.
+//
+// Parameters:
+//
+//	types (*SerializableTypes): The types to include.
+//
+// Returns:
+//
+//	savedWorld: The collected content.
+func (r *Registry) save(types *SerializableTypes) savedWorld {
+	var content savedWorld
+	for index, record := range r.records {
+		if record.archetype == nil {
+			continue
+		}
+		entity := newEntity(uint32(index), r.generations[index])
+		saved := savedEntity{entity: entity}
+		for _, owned := range r.componentsOf(entity) {
+			if entry := types.byType[owned.info.identifier]; entry != nil {
+				saved.components = append(saved.components, savedValue{entry: entry, value: owned.component})
+			}
+		}
+		content.entities = append(content.entities, saved)
+	}
+
+	r.resourcesMutex.RLock()
+	for identifier, resource := range r.resources {
+		if entry := types.byType[identifier]; entry != nil {
+			content.resources = append(content.resources, savedValue{entry: entry, value: resource})
+		}
+	}
+	r.resourcesMutex.RUnlock()
+	sortValues(content.resources)
+
+	for identifier, index := range r.relations {
+		entry := types.byType[identifier]
+		if entry == nil {
+			continue
+		}
+		for _, saved := range content.entities {
+			for _, target := range index.targets[saved.entity] {
+				content.relations = append(content.relations, savedRelation{entry: entry, source: saved.entity, target: target})
+			}
+		}
+	}
+	sortRelations(content.relations)
+	return content
+}
+
+// load creates the entities of saved content and adds their components,
+// resources and relationships, remapping entity identifiers.
+//
+// Parameters:
+//
+//	content (savedWorld): The content to load.
+//
+// Returns:
+//
+//	map[Entity]Entity: The new identifiers, keyed by the identifiers in the content.
+func (r *Registry) load(content savedWorld) map[Entity]Entity {
+	mapping := make(map[Entity]Entity, len(content.entities))
+	for _, saved := range content.entities {
+		mapping[saved.entity] = r.NewEntity()
+	}
+	mapEntity := func(entity Entity) Entity {
+		return mapping[entity]
+	}
+	for _, saved := range content.entities {
+		for _, value := range saved.components {
+			component, ok := mapComponent(value.value, mapEntity)
+			if !ok {
+				continue
+			}
+			info := r.registerComponentStorage(value.entry.identifier, value.entry.newColumn, StorageTable)
+			r.addComponent(info, mapping[saved.entity], component)
+		}
+	}
+	r.resourcesMutex.Lock()
+	for _, resource := range content.resources {
+		r.resources[resource.entry.identifier] = resource.value
+	}
+	r.resourcesMutex.Unlock()
+	for _, relation := range content.relations {
+		source, sourceOK := mapping[relation.source]
+		target, targetOK := mapping[relation.target]
+		if sourceOK && targetOK {
+			r.relationIndex(relation.entry.identifier).link(source, target)
+		}
+	}
+	return mapping
+}
+
+// jsonWorld is the JSON layout of a saved registry.
+type jsonWorld struct {
+	Entities  []jsonEntity   `json:"entities"`
+	Resources []jsonValue    `json:"resources,omitempty"`
+	Relations []jsonRelation `json:"relations,omitempty"`
+}
+
+// jsonEntity is the JSON layout of a saved entity.
+type jsonEntity struct {
+	ID         Entity      `json:"id"`
+	Components []jsonValue `json:"components,omitempty"`
+}
+
+// jsonValue is the JSON layout of a saved component or resource.
+type jsonValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// jsonRelation is the JSON layout of a saved relationship pair.
+type jsonRelation struct {
+	Type   string `json:"type"`
+	Source Entity `json:"source"`
+	Target Entity `json:"target"`
+}
+
+// SaveJSON writes the entities, components, resources and relationships of the
+// registry whose types are registered as indented JSON, e.g. for debugging.
+//
+// Parameters:
+//
+//	writer (io.Writer): The destination of the JSON document.
+//	types (*SerializableTypes): The types to save.
+//
+// Returns:
+//
+//	error: An error if a value cannot be encoded or written.
+func (r *Registry) SaveJSON(writer io.Writer, types *SerializableTypes) error {
+	content := r.save(types)
+	encodeValue := func(value savedValue) (jsonValue, error) {
+		data, err := json.Marshal(value.value)
+		if err != nil {
+			return jsonValue{}, fmt.Errorf("core: encoding %q: %w", value.entry.name, err)
+		}
+		return jsonValue{Type: value.entry.name, Value: data}, nil
+	}
+
+	world := jsonWorld{Entities: make([]jsonEntity, 0, len(content.entities))}
+	for _, saved := range content.entities {
+		entity := jsonEntity{ID: saved.entity}
+		for _, component := range saved.components {
+			value, err := encodeValue(component)
+			if err != nil {
+				return err
+			}
+			entity.Components = append(entity.Components, value)
+		}
+		world.Entities = append(world.Entities, entity)
+	}
+	for _, resource := range content.resources {
+		value, err := encodeValue(resource)
+		if err != nil {
+			return err
+		}
+		world.Resources = append(world.Resources, value)
+	}
+	for _, relation := range content.relations {
+		world.Relations = append(world.Relations, jsonRelation{Type: relation.entry.name, Source: relation.source, Target: relation.target})
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(world)
+}
+
+// LoadJSON reads a JSON document written by SaveJSON and adds its entities,
+// components, resources and relationships to the registry. The entities get new
+// identifiers; references between them in hierarchies, relationships and
+// components implementing EntityMapper are remapped.
+//
+// Parameters:
+//
+//	reader (io.Reader): The source of the JSON document.
+//	types (*SerializableTypes): The types the document may contain.
+//
+// Returns:
+//
+//	map[Entity]Entity: The new identifiers, keyed by the identifiers in the document.
+//	error: An error if the document is malformed or contains an unknown type.
+func (r *Registry) LoadJSON(reader io.Reader, types *SerializableTypes) (map[Entity]Entity, error) {
+	var world jsonWorld
+	if err := json.NewDecoder(reader).Decode(&world); err != nil {
+		return nil, fmt.Errorf("core: decoding world: %w", err)
+	}
+	decodeValue := func(saved jsonValue) (savedValue, error) {
+		entry, err := types.lookup(saved.Type)
+		if err != nil {
+			return savedValue{}, err
+		}
+		value := reflect.New(entry.identifier)
+		if err := json.Unmarshal(saved.Value, value.Interface()); err != nil {
+			return savedValue{}, fmt.Errorf("core: decoding %q: %w", saved.Type, err)
+		}
+		return savedValue{entry: entry, value: value.Elem().Interface()}, nil
+	}
+
+	var content savedWorld
+	for _, entity := range world.Entities {
+		saved := savedEntity{entity: entity.ID}
+		for _, component := range entity.Components {
+			value, err := decodeValue(component)
+			if err != nil {
+				return nil, err
+			}
+			saved.components = append(saved.components, value)
+		}
+		content.entities = append(content.entities, saved)
+	}
+	for _, resource := range world.Resources {
+		value, err := decodeValue(resource)
+		if err != nil {
+			return nil, err
+		}
+		content.resources = append(content.resources, value)
+	}
+	for _, relation := range world.Relations {
+		entry, err := types.lookup(relation.Type)
+		if err != nil {
+			return nil, err
+		}
+		content.relations = append(content.relations, savedRelation{entry: entry, source: relation.Source, target: relation.Target})
+	}
+	return r.load(content), nil
+}
+
+// SaveBinary writes the entities, components, resources and relationships of
+// the registry whose types are registered in a compact binary format for save
+// files. Values are encoded with a single gob stream, so the layout of every type
+// is written only once.
+//
+// Parameters:
+//
+//	writer (io.Writer): The destination of the save.
+//	types (*SerializableTypes): The types to save.
+//
+// Returns:
+//
+//	error: An error if a value cannot be encoded or written.
+func (r *Registry) SaveBinary(writer io.Writer, types *SerializableTypes) error {
+	content := r.save(types)
+	if _, err := io.WriteString(writer, binaryMagic); err != nil {
+		return err
+	}
+
+	var names []string
+	indices := make(map[*serializableType]int)
+	indexOf := func(entry *serializableType) int {
+		if idx, ok := indices[entry]; ok {
+			return idx
+		}
+		indices[entry] = len(names)
+		names = append(names, entry.name)
+		return indices[entry]
+	}
+	for _, saved := range content.entities {
+		for _, component := range saved.components {
+			indexOf(component.entry)
+		}
+	}
+	for _, resource := range content.resources {
+		indexOf(resource.entry)
+	}
+	for _, relation := range content.relations {
+		indexOf(relation.entry)
+	}
+
+	encoder := gob.NewEncoder(writer)
+	encodeValue := func(value savedValue) error {
+		if err := encoder.Encode(indices[value.entry]); err != nil {
+			return err
+		}
+		if value.entry.identifier.Size() == 0 {
+			return nil
+		}
+		if err := encoder.Encode(value.value); err != nil {
+			return fmt.Errorf("core: encoding %q: %w", value.entry.name, err)
+		}
+		return nil
+	}
+
+	if err := encoder.Encode(names); err != nil {
+		return err
+	}
+	if err := encoder.Encode(len(content.entities)); err != nil {
+		return err
+	}
+	for _, saved := range content.entities {
+		if err := encoder.Encode(saved.entity); err != nil {
+			return err
+		}
+		if err := encoder.Encode(len(saved.components)); err != nil {
+			return err
+		}
+		for _, component := range saved.components {
+			if err := encodeValue(component); err != nil {
+				return err
+			}
+		}
+	}
+	if err := encoder.Encode(len(content.resources)); err != nil {
+		return err
+	}
+	for _, resource := range content.resources {
+		if err := encodeValue(resource); err != nil {
+			return err
+		}
+	}
+	if err := encoder.Encode(len(content.relations)); err != nil {
+		return err
+	}
+	for _, relation := range content.relations {
+		if err := encoder.Encode([3]uint64{uint64(indices[relation.entry]), uint64(relation.source), uint64(relation.target)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadBinary reads a save written by SaveBinary and adds its entities,
+// components, resources and relationships to the registry, remapping entity
+// identifiers like LoadJSON.
+//
+// Parameters:
+//
+//	reader (io.Reader): The source of the save.
+//	types (*SerializableTypes): The types the save may contain.
+//
+// Returns:
+//
+//	map[Entity]Entity: The new identifiers, keyed by the identifiers in the save.
+//	error: An error if the save is malformed or contains an unknown type.
+func (r *Registry) LoadBinary(reader io.Reader, types *SerializableTypes) (map[Entity]Entity, error) {
+	magic := make([]byte, len(binaryMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != binaryMagic {
+		return nil, errors.New("core: not a binary world save")
+	}
+
+	decoder := gob.NewDecoder(reader)
+	var names []string
+	if err := decoder.Decode(&names); err != nil {
+		return nil, fmt.Errorf("core: decoding world: %w", err)
+	}
+	entries := make([]*serializableType, len(names))
+	for idx, name := range names {
+		entry, err := types.lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		entries[idx] = entry
+	}
+	entryAt := func(idx int) (*serializableType, error) {
+		if idx < 0 || idx >= len(entries) {
+			return nil, fmt.Errorf("core: invalid type index %d", idx)
+		}
+		return entries[idx], nil
+	}
+	decodeValue := func() (savedValue, error) {
+		var idx int
+		if err := decoder.Decode(&idx); err != nil {
+			return savedValue{}, err
+		}
+		entry, err := entryAt(idx)
+		if err != nil {
+			return savedValue{}, err
+		}
+		value := reflect.New(entry.identifier)
+		if entry.identifier.Size() > 0 {
+			if err := decoder.DecodeValue(value); err != nil {
+				return savedValue{}, fmt.Errorf("core: decoding %q: %w", entry.name, err)
+			}
+		}
+		return savedValue{entry: entry, value: value.Elem().Interface()}, nil
+	}
+
+	var content savedWorld
+	var count int
+	if err := decoder.Decode(&count); err != nil {
+		return nil, fmt.Errorf("core: decoding world: %w", err)
+	}
+	for ; count > 0; count-- {
+		var saved savedEntity
+		var components int
+		if err := decoder.Decode(&saved.entity); err != nil {
+			return nil, fmt.Errorf("core: decoding world: %w", err)
+		}
+		if err := decoder.Decode(&components); err != nil {
+			return nil, fmt.Errorf("core: decoding world: %w", err)
+		}
+		for ; components > 0; components-- {
+			value, err := decodeValue()
+			if err != nil {
+				return nil, err
+			}
+			saved.components = append(saved.components, value)
+		}
+		content.entities = append(content.entities, saved)
+	}
+	if err := decoder.Decode(&count); err != nil {
+		return nil, fmt.Errorf("core: decoding world: %w", err)
+	}
+	for ; count > 0; count-- {
+		value, err := decodeValue()
+		if err != nil {
+			return nil, err
+		}
+		content.resources = append(content.resources, value)
+	}
+	if err := decoder.Decode(&count); err != nil {
+		return nil, fmt.Errorf("core: decoding world: %w", err)
+	}
+	for ; count > 0; count-- {
+		var pair [3]uint64
+		if err := decoder.Decode(&pair); err != nil {
+			return nil, fmt.Errorf("core: decoding world: %w", err)
+		}
+		entry, err := entryAt(int(pair[0]))
+		if err != nil {
+			return nil, err
+		}
+		content.relations = append(content.relations, savedRelation{entry: entry, source: Entity(pair[1]), target: Entity(pair[2])})
+	}
+	return r.load(content), nil
+}
+
+// sortValues orders resources by type name so that saves are deterministic.
+//
+// Parameters:
+//
+//	values ([]savedValue): The values to sort in place.
+func sortValues(values []savedValue) {
+	slices.SortFunc(values, func(a, b savedValue) int {
+		return strings.Compare(a.entry.name, b.entry.name)
+	})
+}
+
+// sortRelations orders relationships by type name, source and target so that
+// saves are deterministic.
+//
+// Parameters:
+//
+//	relations ([]savedRelation): The relationships to sort in place.
+func sortRelations(relations []savedRelation) {
+	slices.SortFunc(relations, func(a, b savedRelation) int {
+		if order := strings.Compare(a.entry.name, b.entry.name); order != 0 {
+			return order
+		}
+		if order := cmp.Compare(a.source, b.source); order != 0 {
+			return order
+		}
+		return cmp.Compare(a.target, b.target)
+	})
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testClock is a resource stored as a pointer, like the game's time.
+type testClock struct {
+	Frame int
+}
+
+// serializeFormat is a pair of save and load functions of one format.
+type serializeFormat struct {
+	name string
+	save func(registry *Registry, writer io.Writer, types *SerializableTypes) error
+	load func(registry *Registry, reader io.Reader, types *SerializableTypes) (map[Entity]Entity, error)
+}
+
+// serializeFormats lists the formats every round-trip test runs with.
+var serializeFormats = []serializeFormat{
+	{"json", (*Registry).SaveJSON, (*Registry).LoadJSON},
+	{"binary", (*Registry).SaveBinary, (*Registry).LoadBinary},
+}
+
+// newTestTypes registers the test types used by the serialization tests.
+func newTestTypes(t *testing.T) *SerializableTypes {
+	t.Helper()
+	types := NewSerializableTypes()
+	for _, err := range []error{
+		RegisterSerializable[testPosition](types, "position"),
+		RegisterSerializable[testTag](types, "tag"),
+		RegisterSerializable[testLikes](types, "likes"),
+		RegisterSerializable[*testClock](types, "clock"),
+	} {
+		if err != nil {
+			t.Fatalf("RegisterSerializable() error = %v", err)
+		}
+	}
+	return types
+}
+
+// TestSaveLoadRoundTrip checks that both formats restore components, zero-size
+// tags, resources, hierarchies and relationships with remapped identifiers, and
+// skip unregistered types.
+func TestSaveLoadRoundTrip(t *testing.T) {
+	for _, format := range serializeFormats {
+		t.Run(format.name, func(t *testing.T) {
+			types := newTestTypes(t)
+			source := NewRegistry()
+			parent, child, other := source.NewEntity(), source.NewEntity(), source.NewEntity()
+			Add(source, parent, testPosition{X: 1, Y: 2})
+			AddTag[testTag](source, parent)
+			Add(source, child, testPosition{X: 3})
+			Add(source, child, testVelocity{DX: 1})
+			source.SetParent(child, parent)
+			Relate[testLikes](source, child, other)
+			InsertResource(source, &testClock{Frame: 7})
+
+			var buffer bytes.Buffer
+			if err := format.save(source, &buffer, types); err != nil {
+				t.Fatalf("save error = %v", err)
+			}
+			destination := NewRegistry()
+			destination.NewEntity()
+			destination.NewEntity()
+			mapping, err := format.load(destination, &buffer, types)
+			if err != nil {
+				t.Fatalf("load error = %v", err)
+			}
+			if len(mapping) != 3 {
+				t.Fatalf("mapping = %v, want 3 entities", mapping)
+			}
+			for _, entity := range []Entity{parent, child, other} {
+				if mapping[entity] == entity || !destination.IsAlive(mapping[entity]) {
+					t.Errorf("%v was mapped to %v, want a new live entity", entity, mapping[entity])
+				}
+			}
+
+			if position, _ := Get[testPosition](destination, mapping[parent]); position != (testPosition{X: 1, Y: 2}) {
+				t.Errorf("parent position = %v, want {1 2}", position)
+			}
+			if !Has[testTag](destination, mapping[parent]) || Has[testTag](destination, mapping[child]) {
+				t.Error("tag was not restored on the parent only")
+			}
+			if Has[testVelocity](destination, mapping[child]) {
+				t.Error("unregistered component was loaded")
+			}
+			if got, _ := Get[Parent](destination, mapping[child]); got.Entity != mapping[parent] {
+				t.Errorf("Parent = %v, want %v", got.Entity, mapping[parent])
+			}
+			if got, _ := Get[Children](destination, mapping[parent]); !reflect.DeepEqual(got.Entities, []Entity{mapping[child]}) {
+				t.Errorf("Children = %v, want [%v]", got.Entities, mapping[child])
+			}
+			if !IsRelated[testLikes](destination, mapping[child], mapping[other]) {
+				t.Error("relationship was not restored")
+			}
+			if clock, ok := Resource[*testClock](destination); !ok || clock.Frame != 7 {
+				t.Errorf("clock = %v, want frame 7", clock)
+			}
+		})
+	}
+}
+
+// TestLoadRejectsUnknownTypes checks that loading fails if a saved type name is
+// not registered with the loading types.
+func TestLoadRejectsUnknownTypes(t *testing.T) {
+	for _, format := range serializeFormats {
+		t.Run(format.name, func(t *testing.T) {
+			source := NewRegistry()
+			Add(source, source.NewEntity(), testPosition{X: 1})
+			var buffer bytes.Buffer
+			if err := format.save(source, &buffer, newTestTypes(t)); err != nil {
+				t.Fatalf("save error = %v", err)
+			}
+
+			destination := NewRegistry()
+			_, err := format.load(destination, &buffer, NewSerializableTypes())
+			if err == nil || !strings.Contains(err.Error(), `unknown serializable type "position"`) {
+				t.Fatalf("load error = %v, want an unknown type error", err)
+			}
+			if len(destination.archetypes[0].entities) != 0 {
+				t.Error("a failed load created entities")
+			}
+		})
+	}
+}
+
+// TestLoadBinaryRejectsBadMagic checks that LoadBinary refuses content that does
+// not start with the binary header.
+func TestLoadBinaryRejectsBadMagic(t *testing.T) {
+	registry := NewRegistry()
+	for _, content := range []string{"", "KECS", "KECS\x02rest", `{"entities":[]}`} {
+		if _, err := registry.LoadBinary(strings.NewReader(content), NewSerializableTypes()); err == nil {
+			t.Errorf("LoadBinary(%q) error = nil, want an error", content)
+		}
+	}
+}
+
+// TestRegisterSerializableRejectsDuplicates checks that names and types can only
+// be registered once.
+func TestRegisterSerializableRejectsDuplicates(t *testing.T) {
+	types := newTestTypes(t)
+	if err := RegisterSerializable[testVelocity](types, "position"); err == nil {
+		t.Error("RegisterSerializable() with a taken name error = nil, want an error")
+	}
+	if err := RegisterSerializable[testPosition](types, "other"); err == nil {
+		t.Error("RegisterSerializable() with a taken type error = nil, want an error")
+	}
+}
@@ -0,0 +1,119 @@
+package core
+
+// StorageKind selects how the components of a type are stored in a registry.
+type StorageKind int
+
+const (
+	// StorageTable stores components in archetype tables. Iteration is fast, but
+	// adding or removing the component moves the entity between archetypes.
+	StorageTable StorageKind = iota
+	// StorageSparseSet stores components in a sparse set outside the archetypes.
+	// Adding and removing the component is O(1), at the cost of slower iteration.
+	// Suited for components that are toggled every few frames.
+	StorageSparseSet
+)
+
+// sparseSet stores the components of a single type indexed by entity slot.
+// Components are packed densely; removing one moves the last into its place.
+type sparseSet struct {
+	indices  []int
+	entities []Entity
+	data     column
+	ticks    componentTicks
+}
+
+// newSparseSet creates an empty sparse set storing its components in data.
+//
+// Parameters:
+//
+//	data (column): The column holding the packed components.
+//
+// Returns:
+//
+//	*sparseSet: The newly created sparse set.
+func newSparseSet(data column) *sparseSet {
+	return &sparseSet{data: data}
+}
+
+// row returns the position of an entity's component in the packed data.
+//
+// Parameters:
+//
+//	entity (Entity): The entity owning the component.
+//
+// Returns:
+//
+//	int: The position of the component.
+//	bool: True if the entity owns a component in the set.
+func (s *sparseSet) row(entity Entity) (int, bool) {
+	index := int(entity.Index())
+	if index >= len(s.indices) || s.indices[index] == 0 {
+		return 0, false
+	}
+	row := s.indices[index] - 1
+	return row, s.entities[row] == entity
+}
+
+// has reports whether an entity owns a component in the set.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to check.
+//
+// Returns:
+//
+//	bool: True if the entity owns a component in the set.
+func (s *sparseSet) has(entity Entity) bool {
+	_, ok := s.row(entity)
+	return ok
+}
+
+// insert stores a component for an entity, replacing any existing one.
+//
+// Parameters:
+//
+//	entity (Entity): The entity owning the component.
+//	component (Component): The component to store.
+//	tick (Tick): The tick at which the component is added or changed.
+func (s *sparseSet) insert(entity Entity, component Component, tick Tick) {
+	if row, ok := s.row(entity); ok {
+		s.data.set(row, component)
+		s.ticks.changed[row] = tick
+		return
+	}
+	index := int(entity.Index())
+	for len(s.indices) <= index {
+		s.indices = append(s.indices, 0)
+	}
+	s.entities = append(s.entities, entity)
+	s.data.push(component)
+	s.ticks.push(tick)
+	s.indices[index] = len(s.entities)
+}
+
+// remove deletes an entity's component from the set.
+//
+// Parameters:
+//
+//	entity (Entity): The entity owning the component.
+//
+// Returns:
+//
+//	bool: True if the entity owned a component in the set.
+func (s *sparseSet) remove(entity Entity) bool {
+	row, ok := s.row(entity)
+	if !ok {
+		return false
+	}
+	last := len(s.entities) - 1
+	if row != last {
+		moved := s.entities[last]
+		s.entities[row] = moved
+		s.indices[moved.Index()] = row + 1
+	}
+	s.entities = s.entities[:last]
+	s.data.swapRemove(row)
+	s.ticks.swapRemove(row)
+	s.indices[entity.Index()] = 0
+	return true
+}
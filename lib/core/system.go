@@ -1,5 +1,19 @@
 package core
 
+// System is updated once per run of the phase it is registered into. Structural
+// changes made while iterating queries must be recorded into commands, which the
+// registry applies once every system of the stage the system runs in has returned.
 type System interface {
-	Update(registry *Registry)
+	Update(registry *Registry, commands *Commands)
+}
+
+// Screen is the target a Renderer draws onto, e.g. the *ebiten.Image passed to a
+// game's Draw. The registry does not depend on a rendering backend, so it passes
+// the screen through unchanged and renderers assert the type they draw onto.
+type Screen = interface{}
+
+// Renderer is a system registered into PhaseRender. It draws onto the screen
+// passed by the game loop.
+type Renderer interface {
+	Draw(registry *Registry, screen Screen)
 }
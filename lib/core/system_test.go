@@ -0,0 +1,114 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// logRenderer appends its label to a shared log whenever it draws.
+type logRenderer struct {
+	label string
+	log   *[]string
+}
+
+// Draw appends the label of the renderer to the log.
+func (lr *logRenderer) Draw(registry *Registry, screen Screen) {
+	*lr.log = append(*lr.log, lr.label)
+}
+
+// TestSystemsOfTheSameTypeRunUnderTheirLabels checks that two instances of the
+// same system type both run and that a taken label is rejected.
+func TestSystemsOfTheSameTypeRunUnderTheirLabels(t *testing.T) {
+	registry := NewRegistry()
+	var log []string
+	if err := registry.AddSystem("first", &logSystem{label: "first", log: &log}); err != nil {
+		t.Fatalf("AddSystem(first) error = %v", err)
+	}
+	if err := registry.AddSystem("second", &logSystem{label: "second", log: &log}); err != nil {
+		t.Fatalf("AddSystem(second) error = %v", err)
+	}
+	err := registry.AddSystem("first", &logSystem{label: "duplicate", log: &log})
+	if want := `core: system "first" is already registered`; err == nil || err.Error() != want {
+		t.Fatalf("AddSystem(duplicate) error = %v, want %s", err, want)
+	}
+	err = registry.AddRenderer("second", &logRenderer{label: "duplicate", log: &log})
+	if want := `core: system "second" is already registered`; err == nil || err.Error() != want {
+		t.Fatalf("AddRenderer(duplicate) error = %v, want %s", err, want)
+	}
+
+	if err := registry.RunPhase(PhaseUpdate); err != nil {
+		t.Fatalf("RunPhase() error = %v", err)
+	}
+	if want := []string{"first", "second"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("ran %v, want %v", log, want)
+	}
+	if got := len(registry.GetSystems()); got != 2 {
+		t.Errorf("len(GetSystems()) = %d, want 2", got)
+	}
+}
+
+// TestDisabledSystemKeepsItsPlace checks that a disabled system is skipped while
+// the systems depending on it keep their order, and that it runs in the same
+// place once enabled again.
+func TestDisabledSystemKeepsItsPlace(t *testing.T) {
+	registry := NewRegistry()
+	var log []string
+	registry.AddSystem("a", &logSystem{label: "a", log: &log})
+	registry.AddSystem("b", &logSystem{label: "b", log: &log}, After("a"))
+	registry.AddSystem("c", &logSystem{label: "c", log: &log}, After("b"), WithPriority(10))
+
+	if err := registry.SetSystemEnabled("b", false); err != nil {
+		t.Fatalf("SetSystemEnabled(b, false) error = %v", err)
+	}
+	if registry.IsSystemEnabled("b") {
+		t.Error("IsSystemEnabled(b) = true, want false")
+	}
+	if err := registry.RunPhase(PhaseUpdate); err != nil {
+		t.Fatalf("RunPhase() error = %v", err)
+	}
+	if want := []string{"a", "c"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("ran %v while b is disabled, want %v", log, want)
+	}
+
+	log = nil
+	registry.SetSystemEnabled("b", true)
+	if !registry.IsSystemEnabled("b") {
+		t.Error("IsSystemEnabled(b) = false, want true")
+	}
+	registry.RunPhase(PhaseUpdate)
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("ran %v after enabling b, want %v", log, want)
+	}
+
+	if err := registry.SetSystemEnabled("missing", false); err == nil {
+		t.Error("SetSystemEnabled(missing) error = nil, want an error")
+	}
+	if registry.IsSystemEnabled("missing") {
+		t.Error("IsSystemEnabled(missing) = true, want false")
+	}
+}
+
+// TestRemoveRenderer checks that a removed renderer no longer draws and that its
+// label can be reused.
+func TestRemoveRenderer(t *testing.T) {
+	registry := NewRegistry()
+	var log []string
+	registry.AddRenderer("world", &logRenderer{label: "world", log: &log})
+	registry.AddRenderer("hud", &logRenderer{label: "hud", log: &log}, After("world"))
+
+	if !registry.RemoveSystem("hud") {
+		t.Fatal("RemoveSystem(hud) = false, want true")
+	}
+	if registry.RemoveSystem("hud") {
+		t.Error("RemoveSystem(hud) twice = true, want false")
+	}
+	if err := registry.RunRenderPhase(nil); err != nil {
+		t.Fatalf("RunRenderPhase() error = %v", err)
+	}
+	if want := []string{"world"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("drew %v, want %v", log, want)
+	}
+	if err := registry.AddRenderer("hud", &logRenderer{label: "hud", log: &log}); err != nil {
+		t.Errorf("AddRenderer(hud) after removal error = %v", err)
+	}
+}
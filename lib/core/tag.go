@@ -0,0 +1,58 @@
+package core
+
+import "fmt"
+
+// AddTag marks an entity with the tag type T. Tags are zero-size component types,
+// usually empty structs such as `type Player struct{}`, that cost no storage
+// beyond the archetype membership of the entity. Tags are queried with With and
+// Without, checked with Has and removed with Remove like any other component.
+// Tags in archetype tables do not track change ticks, so Added and Changed never
+// match them; Removed does.
+//
+// Parameters:
+//
+//	registry (*Registry): The registry holding the entity.
+//	entity (Entity): The entity to mark.
+//
+// Returns:
+//
+//	error: An error if T is not a zero-size type.
+func AddTag[T any](registry *Registry, entity Entity) error {
+	if err := checkTag[T](); err != nil {
+		return err
+	}
+	var tag T
+	Add(registry, entity, tag)
+	return nil
+}
+
+// AddTagDeferred records marking an entity with the tag type T.
+//
+// Parameters:
+//
+//	commands (*Commands): The command buffer to record into.
+//	entity (Entity): The entity to mark.
+//
+// Returns:
+//
+//	error: An error if T is not a zero-size type, in which case nothing is recorded.
+func AddTagDeferred[T any](commands *Commands, entity Entity) error {
+	if err := checkTag[T](); err != nil {
+		return err
+	}
+	var tag T
+	AddDeferred(commands, entity, tag)
+	return nil
+}
+
+// checkTag verifies that T is a zero-size type and can be used as a tag.
+//
+// Returns:
+//
+//	error: An error if T has a size.
+func checkTag[T any]() error {
+	if identifier := componentType[T](); identifier.Size() != 0 {
+		return fmt.Errorf("core: %v is not a zero-size tag type", identifier)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTagsCostNoPerEntityStorage checks that tag columns neither store values nor
+// track ticks, while tags still work with queries, Has and Removed.
+func TestTagsCostNoPerEntityStorage(t *testing.T) {
+	registry := NewRegistry()
+	var tagged []Entity
+	for i := 0; i < 10; i++ {
+		entity := registry.NewEntity()
+		Add(registry, entity, testPosition{X: float64(i)})
+		if i%2 == 0 {
+			if err := AddTag[testTag](registry, entity); err != nil {
+				t.Fatalf("AddTag() error = %v", err)
+			}
+			tagged = append(tagged, entity)
+		}
+	}
+
+	info := registry.componentInfo(componentType[testTag]())
+	for _, arch := range registry.archetypes {
+		if !arch.has(info.id) {
+			continue
+		}
+		if _, ok := arch.column(info.id).(*tagColumn); !ok {
+			t.Errorf("tag column is a %T, want *tagColumn", arch.column(info.id))
+		}
+		if ticks := arch.columnTicks(info.id); ticks != nil {
+			t.Errorf("tag column tracks %d ticks, want none", len(ticks.added))
+		}
+	}
+
+	var with, without []Entity
+	NewQuery1[testPosition](registry, With[testTag]()).Each(func(entity Entity, _ testPosition) {
+		with = append(with, entity)
+	})
+	NewQuery1[testPosition](registry, Without[testTag]()).Each(func(entity Entity, _ testPosition) {
+		without = append(without, entity)
+	})
+	if !reflect.DeepEqual(with, tagged) || len(without) != 5 {
+		t.Errorf("With() = %v, Without() = %v, want %v and 5 others", with, without, tagged)
+	}
+	if !MarkChanged[testTag](registry, tagged[0]) {
+		t.Error("MarkChanged() on a tag = false, want true")
+	}
+	NewQuery1[testTag](registry, Added[testTag](0)).Each(func(entity Entity, _ testTag) {
+		t.Errorf("Added() matched tag of %v", entity)
+	})
+
+	registry.AdvanceFrame()
+	Remove[testTag](registry, tagged[1])
+	registry.DestroyEntity(tagged[2])
+	if got, want := Removed[testTag](registry, 0), tagged[1:3]; !reflect.DeepEqual(got, want) {
+		t.Errorf("Removed() = %v, want %v", got, want)
+	}
+	if !Has[testTag](registry, tagged[0]) || Has[testTag](registry, tagged[1]) {
+		t.Error("Has() does not reflect the remaining tags")
+	}
+}
+
+// TestAddTagRejectsSizedTypes checks that AddTag and AddTagDeferred refuse types
+// carrying data.
+func TestAddTagRejectsSizedTypes(t *testing.T) {
+	registry := NewRegistry()
+	entity := registry.NewEntity()
+	if err := AddTag[testPosition](registry, entity); err == nil {
+		t.Error("AddTag() error = nil, want an error for a sized type")
+	}
+	commands := newCommands(registry)
+	if err := AddTagDeferred[testPosition](commands, entity); err == nil {
+		t.Error("AddTagDeferred() error = nil, want an error for a sized type")
+	}
+	commands.flush()
+	if Has[testPosition](registry, entity) {
+		t.Error("a sized type was added as a tag")
+	}
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EntityMapper is implemented by components that refer to other entities. When
+// entities are copied to another registry, their identifiers change, and such
+// components are replaced by the result of MapEntities. Entities that were not
+// copied along are mapped to NilEntity.
+type EntityMapper interface {
+	MapEntities(mapEntity func(entity Entity) Entity) Component
+}
+
+// ownedComponent is a component of an entity together with its type.
+type ownedComponent struct {
+	info      *componentInfo
+	component Component
+}
+
+// componentsOf returns every component an entity owns.
+//
+// Parameters:
+//
+//	entity (Entity): The entity, which must be alive.
+//
+// Returns:
+//
+//	[]ownedComponent: The components, table components first.
+func (r *Registry) componentsOf(entity Entity) []ownedComponent {
+	record := r.records[entity.Index()]
+	owned := make([]ownedComponent, 0, len(record.archetype.components))
+	for idx, info := range record.archetype.components {
+		owned = append(owned, ownedComponent{info: info, component: record.archetype.columns[idx].get(record.row)})
+	}
+	for _, info := range r.sparseInfos {
+		if row, ok := info.sparse.row(entity); ok {
+			owned = append(owned, ownedComponent{info: info, component: info.sparse.data.get(row)})
+		}
+	}
+	return owned
+}
+
+// TransferEntity moves an entity with all its components and descendants to
+// another registry. See TransferEntities.
+//
+// Parameters:
+//
+//	entity (Entity): The entity to move.
+//	destination (*Registry): The registry to move the entity to.
+//
+// Returns:
+//
+//	Entity: The identifier of the entity in the destination registry.
+//	error: An error if the entity is not alive or the destination is the registry itself.
+func (r *Registry) TransferEntity(entity Entity, destination *Registry) (Entity, error) {
+	mapping, err := r.TransferEntities([]Entity{entity}, destination)
+	if err != nil {
+		return NilEntity, err
+	}
+	return mapping[entity], nil
+}
+
+// TransferEntities moves entities with all their components and descendants to
+// another registry, e.g. from a loading world into the gameplay world. The
+// entities get new identifiers in the destination. Hierarchies, relationships and
+// components implementing EntityMapper are remapped among the moved entities;
+// links to entities that stay behind are dropped. Add hooks run in the
+// destination, remove hooks do not run in the source. Neither registry may be in
+// use by other goroutines during the transfer.
+//
+// Parameters:
+//
+//	entities ([]Entity): The entities to move.
+//	destination (*Registry): The registry to move the entities to.
+//
+// Returns:
+//
+//	map[Entity]Entity: The identifiers in the destination, keyed by the identifiers in this registry, including descendants.
+//	error: An error if an entity is not alive or the destination is the registry itself.
+func (r *Registry) TransferEntities(entities []Entity, destination *Registry) (map[Entity]Entity, error) {
+	if destination == r {
+		return nil, errors.New("core: cannot transfer entities to the registry they belong to")
+	}
+	for _, entity := range entities {
+		if !r.IsAlive(entity) {
+			return nil, fmt.Errorf("core: cannot transfer %v, it is not alive", entity)
+		}
+	}
+
+	mapping := make(map[Entity]Entity)
+	var moved []Entity
+	var collect func(entity Entity)
+	collect = func(entity Entity) {
+		if _, ok := mapping[entity]; ok {
+			return
+		}
+		mapping[entity] = destination.NewEntity()
+		moved = append(moved, entity)
+		if children, ok := Get[Children](r, entity); ok {
+			for _, child := range children.Entities {
+				collect(child)
+			}
+		}
+	}
+	for _, entity := range entities {
+		collect(entity)
+	}
+
+	r.copyEntities(moved, mapping, destination)
+	for _, entity := range entities {
+		r.destroyEntity(entity, false)
+	}
+	return mapping, nil
+}
+
+// copyEntities adds the components and relationships of entities to their
+// counterparts in another registry.
+//
+// Parameters:
+//
+//	entities ([]Entity): The entities to copy.
+//	mapping (map[Entity]Entity): The counterparts in the destination of every copied entity.
+//	destination (*Registry): The registry to copy the entities to.
+func (r *Registry) copyEntities(entities []Entity, mapping map[Entity]Entity, destination *Registry) {
+	mapEntity := func(entity Entity) Entity {
+		return mapping[entity]
+	}
+	for _, entity := range entities {
+		for _, owned := range r.componentsOf(entity) {
+			component, ok := mapComponent(owned.component, mapEntity)
+			if !ok {
+				continue
+			}
+			info := destination.registerComponentStorage(owned.info.identifier, owned.info.newColumn, owned.info.storage)
+			destination.addComponent(info, mapping[entity], component)
+		}
+	}
+	for identifier, index := range r.relations {
+		for _, source := range entities {
+			for _, target := range index.targets[source] {
+				if mapped, ok := mapping[target]; ok {
+					destination.relationIndex(identifier).link(mapping[source], mapped)
+				}
+			}
+		}
+	}
+}
+
+// mapComponent remaps the entities a component refers to if it implements
+// EntityMapper. A Parent whose entity was not copied along is dropped, so that
+// the child becomes a root entity.
+//
+// Parameters:
+//
+//	component (Component): The component to remap.
+//	mapEntity (func(Entity) Entity): Maps an entity to its new identifier, or to NilEntity.
+//
+// Returns:
+//
+//	Component: The remapped component.
+//	bool: False if the component is to be dropped.
+func mapComponent(component Component, mapEntity func(entity Entity) Entity) (Component, bool) {
+	if mapper, ok := component.(EntityMapper); ok {
+		component = mapper.MapEntities(mapEntity)
+	}
+	if parent, ok := component.(Parent); ok && parent.Entity == NilEntity {
+		return nil, false
+	}
+	return component, true
+}
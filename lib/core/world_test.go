@@ -0,0 +1,161 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testLikes is a relationship type.
+type testLikes struct{}
+
+// testTarget is a component referring to another entity.
+type testTarget struct {
+	Entity Entity
+}
+
+// MapEntities returns the component referring to the remapped entity.
+func (t testTarget) MapEntities(mapEntity func(entity Entity) Entity) Component {
+	return testTarget{Entity: mapEntity(t.Entity)}
+}
+
+// TestTransferHierarchy checks that a parent is moved together with its children
+// and that the hierarchy is remapped in the destination.
+func TestTransferHierarchy(t *testing.T) {
+	source, destination := NewRegistry(), NewRegistry()
+	destination.NewEntity()
+	parent, first, second := source.NewEntity(), source.NewEntity(), source.NewEntity()
+	Add(source, parent, testPosition{X: 1})
+	Add(source, first, testPosition{X: 2})
+	source.SetParent(first, parent)
+	source.SetParent(second, parent)
+
+	mapping, err := source.TransferEntities([]Entity{parent}, destination)
+	if err != nil {
+		t.Fatalf("TransferEntities() error = %v", err)
+	}
+	if len(mapping) != 3 {
+		t.Fatalf("mapping = %v, want the parent and both children", mapping)
+	}
+	for _, entity := range []Entity{parent, first, second} {
+		if source.IsAlive(entity) {
+			t.Errorf("%v is still alive in the source", entity)
+		}
+		if !destination.IsAlive(mapping[entity]) {
+			t.Errorf("%v is not alive in the destination", mapping[entity])
+		}
+	}
+
+	children, _ := Get[Children](destination, mapping[parent])
+	if want := []Entity{mapping[first], mapping[second]}; !reflect.DeepEqual(children.Entities, want) {
+		t.Errorf("Children = %v, want %v", children.Entities, want)
+	}
+	for _, child := range []Entity{first, second} {
+		if got, _ := Get[Parent](destination, mapping[child]); got.Entity != mapping[parent] {
+			t.Errorf("Parent of %v = %v, want %v", mapping[child], got.Entity, mapping[parent])
+		}
+	}
+	if position, _ := Get[testPosition](destination, mapping[first]); position.X != 2 {
+		t.Errorf("position = %v, want X 2", position)
+	}
+}
+
+// TestTransferChildLeavesParentBehind checks that moving a child detaches it
+// from a parent that stays in the source.
+func TestTransferChildLeavesParentBehind(t *testing.T) {
+	source, destination := NewRegistry(), NewRegistry()
+	parent, moved, kept := source.NewEntity(), source.NewEntity(), source.NewEntity()
+	source.SetParent(moved, parent)
+	source.SetParent(kept, parent)
+
+	entity, err := source.TransferEntity(moved, destination)
+	if err != nil {
+		t.Fatalf("TransferEntity() error = %v", err)
+	}
+	if Has[Parent](destination, entity) {
+		t.Error("moved child still has a Parent")
+	}
+	children, _ := Get[Children](source, parent)
+	if !reflect.DeepEqual(children.Entities, []Entity{kept}) {
+		t.Errorf("Children in the source = %v, want [%v]", children.Entities, kept)
+	}
+}
+
+// TestTransferRemapsReferences checks that relationships and EntityMapper
+// components are remapped among the moved entities and dropped otherwise.
+func TestTransferRemapsReferences(t *testing.T) {
+	source, destination := NewRegistry(), NewRegistry()
+	first, second, stays := source.NewEntity(), source.NewEntity(), source.NewEntity()
+	Relate[testLikes](source, first, second)
+	Relate[testLikes](source, first, stays)
+	Relate[testLikes](source, stays, second)
+	Add(source, first, testTarget{Entity: second})
+	Add(source, second, testTarget{Entity: stays})
+
+	mapping, err := source.TransferEntities([]Entity{first, second}, destination)
+	if err != nil {
+		t.Fatalf("TransferEntities() error = %v", err)
+	}
+	if got := Targets[testLikes](destination, mapping[first]); !reflect.DeepEqual(got, []Entity{mapping[second]}) {
+		t.Errorf("Targets() = %v, want [%v]", got, mapping[second])
+	}
+	if got := Targets[testLikes](source, stays); len(got) != 0 {
+		t.Errorf("Targets() of the entity left behind = %v, want none", got)
+	}
+	if got, _ := Get[testTarget](destination, mapping[first]); got.Entity != mapping[second] {
+		t.Errorf("remapped target = %v, want %v", got.Entity, mapping[second])
+	}
+	if got, _ := Get[testTarget](destination, mapping[second]); got.Entity != NilEntity {
+		t.Errorf("target left behind = %v, want %v", got.Entity, NilEntity)
+	}
+}
+
+// TestTransferHooksAndStorage checks that sparse-set components are moved, that
+// add hooks run in the destination and that remove hooks do not run in the source.
+func TestTransferHooksAndStorage(t *testing.T) {
+	source, destination := NewRegistry(), NewRegistry()
+	RegisterComponent[testVelocity](source, StorageSparseSet)
+	OnRemove(source, func(*Registry, Entity, testVelocity) {
+		t.Error("remove hook ran in the source")
+	})
+	added := 0
+	OnAdd(destination, func(*Registry, Entity, testVelocity) {
+		added++
+	})
+	entity := source.NewEntity()
+	Add(source, entity, testVelocity{DX: 3})
+
+	moved, err := source.TransferEntity(entity, destination)
+	if err != nil {
+		t.Fatalf("TransferEntity() error = %v", err)
+	}
+	if velocity, _ := Get[testVelocity](destination, moved); velocity.DX != 3 {
+		t.Errorf("velocity = %v, want DX 3", velocity)
+	}
+	if added != 1 {
+		t.Errorf("add hook ran %d times, want 1", added)
+	}
+	if Has[testVelocity](source, entity) {
+		t.Error("source still holds the sparse component")
+	}
+}
+
+// TestTransferErrors checks that transfers to the registry itself and of dead
+// entities are rejected without moving anything.
+func TestTransferErrors(t *testing.T) {
+	source, destination := NewRegistry(), NewRegistry()
+	alive, dead := source.NewEntity(), source.NewEntity()
+	source.DestroyEntity(dead)
+
+	if _, err := source.TransferEntity(alive, source); err == nil {
+		t.Error("TransferEntity() to itself error = nil, want an error")
+	}
+	if _, err := source.TransferEntities([]Entity{alive, dead}, destination); err == nil {
+		t.Error("TransferEntities() of a dead entity error = nil, want an error")
+	}
+	if !source.IsAlive(alive) {
+		t.Error("a failed transfer destroyed the entity")
+	}
+	if len(destination.archetypes[0].entities) != 0 {
+		t.Error("a failed transfer created entities in the destination")
+	}
+}
@@ -0,0 +1,167 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// recordingHeader starts every recording file.
+const recordingHeader = "kro-ecs input v1"
+
+// Recording holds the keys held down during every tick of a session, so that the
+// session can be replayed deterministically.
+type Recording struct {
+	Ticks [][]ebiten.Key
+}
+
+// NewRecording creates an empty recording.
+//
+// Returns:
+//
+//	*Recording: A pointer to the newly created Recording instance.
+func NewRecording() *Recording {
+	return &Recording{}
+}
+
+// Record appends the keys held down during the next tick. The keys are copied
+// and sorted.
+//
+// Parameters:
+//
+//	keys ([]ebiten.Key): The keys held down.
+func (r *Recording) Record(keys []ebiten.Key) {
+	tick := slices.Clone(keys)
+	slices.Sort(tick)
+	r.Ticks = append(r.Ticks, tick)
+}
+
+// Keys returns the keys held down during a tick.
+//
+// Parameters:
+//
+//	tick (int): The tick, counted from 0.
+//
+// Returns:
+//
+//	[]ebiten.Key: The keys held down, or nil once the recording has ended.
+func (r *Recording) Keys(tick int) []ebiten.Key {
+	if tick < 0 || tick >= len(r.Ticks) {
+		return nil
+	}
+	return r.Ticks[tick]
+}
+
+// Len returns the number of recorded ticks.
+//
+// Returns:
+//
+//	int: The number of ticks.
+func (r *Recording) Len() int {
+	return len(r.Ticks)
+}
+
+// Save writes the recording as text, one line per tick listing the names of the
+// keys held down.
+//
+// Parameters:
+//
+//	writer (io.Writer): The destination of the recording.
+//
+// Returns:
+//
+//	error: An error if the recording cannot be written.
+func (r *Recording) Save(writer io.Writer) error {
+	buffered := bufio.NewWriter(writer)
+	fmt.Fprintln(buffered, recordingHeader)
+	for _, tick := range r.Ticks {
+		names := make([]string, len(tick))
+		for idx, key := range tick {
+			names[idx] = key.String()
+		}
+		fmt.Fprintln(buffered, strings.Join(names, " "))
+	}
+	return buffered.Flush()
+}
+
+// SaveFile writes the recording to a file, replacing it if it exists.
+//
+// Parameters:
+//
+//	path (string): The path of the file.
+//
+// Returns:
+//
+//	error: An error if the file cannot be written.
+func (r *Recording) SaveFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadRecording reads a recording written by Save.
+//
+// Parameters:
+//
+//	reader (io.Reader): The source of the recording.
+//
+// Returns:
+//
+//	*Recording: The recording.
+//	error: An error if the recording is malformed or names an unknown key.
+func LoadRecording(reader io.Reader) (*Recording, error) {
+	scanner := bufio.NewScanner(reader)
+	if !scanner.Scan() || scanner.Text() != recordingHeader {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("input: not an input recording")
+	}
+
+	recording := NewRecording()
+	for line := 2; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		tick := make([]ebiten.Key, len(fields))
+		for idx, name := range fields {
+			if err := tick[idx].UnmarshalText([]byte(name)); err != nil {
+				return nil, fmt.Errorf("input: line %d: %w", line, err)
+			}
+		}
+		recording.Ticks = append(recording.Ticks, tick)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return recording, nil
+}
+
+// LoadRecordingFile reads a recording from a file written by SaveFile.
+//
+// Parameters:
+//
+//	path (string): The path of the file.
+//
+// Returns:
+//
+//	*Recording: The recording.
+//	error: An error if the file cannot be read or is malformed.
+func LoadRecordingFile(path string) (*Recording, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadRecording(file)
+}
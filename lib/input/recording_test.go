@@ -0,0 +1,89 @@
+package input
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestRecordingRoundTrip checks that a recording written by Save is read back
+// unchanged by LoadRecording, including ticks without keys.
+func TestRecordingRoundTrip(t *testing.T) {
+	recording := NewRecording()
+	recording.Record([]ebiten.Key{ebiten.KeyD})
+	recording.Record([]ebiten.Key{ebiten.KeyShift, ebiten.KeyD})
+	recording.Record(nil)
+	recording.Record([]ebiten.Key{ebiten.KeyArrowUp})
+
+	var buffer bytes.Buffer
+	if err := recording.Save(&buffer); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadRecording(&buffer)
+	if err != nil {
+		t.Fatalf("LoadRecording() error = %v", err)
+	}
+	if loaded.Len() != recording.Len() {
+		t.Fatalf("Len() = %d, want %d", loaded.Len(), recording.Len())
+	}
+	for tick := 0; tick < recording.Len(); tick++ {
+		got, want := loaded.Keys(tick), recording.Keys(tick)
+		if len(got) != len(want) || (len(want) > 0 && !reflect.DeepEqual(got, want)) {
+			t.Errorf("Keys(%d) = %v, want %v", tick, got, want)
+		}
+	}
+	if keys := loaded.Keys(recording.Len()); keys != nil {
+		t.Errorf("Keys(%d) = %v, want nil after the end", recording.Len(), keys)
+	}
+}
+
+// TestRecordSortsAndCopies checks that Record keeps a sorted copy of the keys.
+func TestRecordSortsAndCopies(t *testing.T) {
+	keys := []ebiten.Key{ebiten.KeyShift, ebiten.KeyA}
+	recording := NewRecording()
+	recording.Record(keys)
+	keys[0] = ebiten.KeyZ
+
+	if got, want := recording.Keys(0), []ebiten.Key{ebiten.KeyA, ebiten.KeyShift}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys(0) = %v, want %v", got, want)
+	}
+}
+
+// TestLoadRecordingErrors checks the errors reported for malformed recordings.
+func TestLoadRecordingErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "empty",
+			data: "",
+			want: "input: not an input recording",
+		},
+		{
+			name: "bad header",
+			data: "kro-ecs input v2\nD\n",
+			want: "input: not an input recording",
+		},
+		{
+			name: "unknown key",
+			data: recordingHeader + "\nD\nD Hyper\n",
+			want: "input: line 3: ",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recording, err := LoadRecording(strings.NewReader(test.data))
+			if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+				t.Fatalf("LoadRecording() error = %v, want %q", err, test.want)
+			}
+			if recording != nil {
+				t.Errorf("LoadRecording() = %v, want nil", recording)
+			}
+		})
+	}
+}
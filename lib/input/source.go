@@ -0,0 +1,172 @@
+package input
+
+import (
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// State is the input held during a single tick.
+type State struct {
+	Keys         []ebiten.Key
+	MouseButtons []ebiten.MouseButton
+	CursorX      int
+	CursorY      int
+	Gamepads     []GamepadState
+}
+
+// GamepadState is the input of a gamepad with the standard layout during a single tick.
+type GamepadState struct {
+	ID      ebiten.GamepadID
+	Buttons []ebiten.StandardGamepadButton
+	Axes    [ebiten.StandardGamepadAxisMax + 1]float64
+}
+
+// IsKeyPressed reports whether a key is held down.
+//
+// Parameters:
+//
+//	key (ebiten.Key): The key to check.
+//
+// Returns:
+//
+//	bool: True if the key is held down.
+func (s State) IsKeyPressed(key ebiten.Key) bool {
+	return slices.Contains(s.Keys, key)
+}
+
+// IsMouseButtonPressed reports whether a mouse button is held down.
+//
+// Parameters:
+//
+//	button (ebiten.MouseButton): The button to check.
+//
+// Returns:
+//
+//	bool: True if the button is held down.
+func (s State) IsMouseButtonPressed(button ebiten.MouseButton) bool {
+	return slices.Contains(s.MouseButtons, button)
+}
+
+// Source provides the input state of every tick. Systems read input only through
+// a Source, so that they can run without a window.
+type Source interface {
+	// Poll returns the input state of the current tick. It is called once per tick.
+	Poll() State
+}
+
+// EbitenSource reads the input state from ebiten's keyboard, mouse and gamepad state.
+type EbitenSource struct {
+	gamepads []ebiten.GamepadID
+}
+
+// NewEbitenSource creates and returns a new instance of EbitenSource.
+//
+// Returns:
+//
+//	*EbitenSource: A pointer to the newly created EbitenSource instance.
+func NewEbitenSource() *EbitenSource {
+	return &EbitenSource{}
+}
+
+// Poll returns the keys, mouse buttons, cursor position and standard-layout
+// gamepads ebiten reports for the current tick. Keys include the virtual keys
+// such as ebiten.KeyShift.
+//
+// Returns:
+//
+//	State: The input state, with keys and buttons sorted.
+func (es *EbitenSource) Poll() State {
+	var state State
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		if ebiten.IsKeyPressed(key) {
+			state.Keys = append(state.Keys, key)
+		}
+	}
+	slices.Sort(state.Keys)
+	for button := ebiten.MouseButton(0); button <= ebiten.MouseButtonMax; button++ {
+		if ebiten.IsMouseButtonPressed(button) {
+			state.MouseButtons = append(state.MouseButtons, button)
+		}
+	}
+	state.CursorX, state.CursorY = ebiten.CursorPosition()
+
+	es.gamepads = ebiten.AppendGamepadIDs(es.gamepads[:0])
+	for _, id := range es.gamepads {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		gamepad := GamepadState{ID: id}
+		for button := ebiten.StandardGamepadButton(0); button <= ebiten.StandardGamepadButtonMax; button++ {
+			if ebiten.IsStandardGamepadButtonPressed(id, button) {
+				gamepad.Buttons = append(gamepad.Buttons, button)
+			}
+		}
+		for axis := ebiten.StandardGamepadAxis(0); axis <= ebiten.StandardGamepadAxisMax; axis++ {
+			gamepad.Axes[axis] = ebiten.StandardGamepadAxisValue(id, axis)
+		}
+		state.Gamepads = append(state.Gamepads, gamepad)
+	}
+	return state
+}
+
+// ScriptedSource plays back a fixed sequence of input states, one per tick, e.g.
+// in tests or when replaying a recording. Once the script has ended, no input is
+// held.
+type ScriptedSource struct {
+	States []State
+	tick   int
+}
+
+// NewScriptedSource creates a source playing back the given states.
+//
+// Parameters:
+//
+//	states (...State): The input state of every tick, starting with the first.
+//
+// Returns:
+//
+//	*ScriptedSource: A pointer to the newly created ScriptedSource instance.
+func NewScriptedSource(states ...State) *ScriptedSource {
+	return &ScriptedSource{States: states}
+}
+
+// NewReplaySource creates a source playing back the keys of a recording.
+//
+// Parameters:
+//
+//	recording (*Recording): The recording to replay.
+//
+// Returns:
+//
+//	*ScriptedSource: A pointer to the newly created ScriptedSource instance.
+func NewReplaySource(recording *Recording) *ScriptedSource {
+	states := make([]State, recording.Len())
+	for tick := range states {
+		states[tick].Keys = recording.Keys(tick)
+	}
+	return NewScriptedSource(states...)
+}
+
+// Poll returns the state of the current tick and advances the script.
+//
+// Returns:
+//
+//	State: The scripted input state, or the empty state once the script has ended.
+func (ss *ScriptedSource) Poll() State {
+	if ss.tick >= len(ss.States) {
+		return State{}
+	}
+	state := ss.States[ss.tick]
+	ss.tick++
+	return state
+}
+
+// Done reports whether every scripted state has been polled.
+//
+// Returns:
+//
+//	bool: True if the script has ended.
+func (ss *ScriptedSource) Done() bool {
+	return ss.tick >= len(ss.States)
+}
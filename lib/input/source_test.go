@@ -0,0 +1,46 @@
+package input
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestScriptedSourcePlaysStatesInOrder checks that every Poll returns the next
+// scripted state and that the empty state is returned once the script has ended.
+func TestScriptedSourcePlaysStatesInOrder(t *testing.T) {
+	states := []State{
+		{Keys: []ebiten.Key{ebiten.KeyA}},
+		{Keys: []ebiten.Key{ebiten.KeyA, ebiten.KeyB}, CursorX: 3, CursorY: 4},
+	}
+	source := NewScriptedSource(states...)
+	for tick, want := range states {
+		if source.Done() {
+			t.Fatalf("Done() = true before tick %d", tick)
+		}
+		if got := source.Poll(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Poll() at tick %d = %+v, want %+v", tick, got, want)
+		}
+	}
+	if !source.Done() {
+		t.Error("Done() = false after the last state")
+	}
+	if got := source.Poll(); !reflect.DeepEqual(got, State{}) {
+		t.Errorf("Poll() after the end = %+v, want the empty state", got)
+	}
+}
+
+// TestStatePressed checks the key and mouse button lookups of a State.
+func TestStatePressed(t *testing.T) {
+	state := State{
+		Keys:         []ebiten.Key{ebiten.KeyW},
+		MouseButtons: []ebiten.MouseButton{ebiten.MouseButtonLeft},
+	}
+	if !state.IsKeyPressed(ebiten.KeyW) || state.IsKeyPressed(ebiten.KeyS) {
+		t.Errorf("IsKeyPressed() does not match the held keys %v", state.Keys)
+	}
+	if !state.IsMouseButtonPressed(ebiten.MouseButtonLeft) || state.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+		t.Errorf("IsMouseButtonPressed() does not match the held buttons %v", state.MouseButtons)
+	}
+}
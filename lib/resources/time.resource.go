@@ -0,0 +1,22 @@
+package resources
+
+import "time"
+
+// Time holds the timing of the current frame. The game advances it once per
+// update, before any system runs.
+type Time struct {
+	Delta   time.Duration
+	Elapsed time.Duration
+	Frame   uint64
+}
+
+// Advance moves the time forward by one frame.
+//
+// Parameters:
+//
+//	delta (time.Duration): The duration of the frame.
+func (t *Time) Advance(delta time.Duration) {
+	t.Delta = delta
+	t.Elapsed += delta
+	t.Frame++
+}
@@ -1,8 +1,6 @@
 package systems
 
 import (
-	"reflect"
-
 	"github.com/Djosar/kro-ecs/lib/components"
 	"github.com/Djosar/kro-ecs/lib/core"
 )
@@ -29,17 +27,15 @@ func NewAnimationSystem() *AnimationSystem {
 // Parameters:
 //
 //	registry (*core.Registry): The registry containing all entities and components in the ECS.
-func (as *AnimationSystem) Update(registry *core.Registry) {
-	transfType := reflect.TypeOf(&components.TransformComponent{})
-	animType := reflect.TypeOf(&components.AnimationComponent{})
-
-	for entity, component := range registry.GetAllComponentsOfType(transfType) {
-		transform := component.(*components.TransformComponent)
-		animationComp := registry.GetComponent(animType, entity).(*components.AnimationComponent)
+//	commands (*core.Commands): The command buffer for structural changes.
+func (as *AnimationSystem) Update(registry *core.Registry, commands *core.Commands) {
+	query := core.NewQuery2[*components.TransformComponent, *components.AnimationComponent](registry)
+	query.Each(func(entity core.Entity, transform *components.TransformComponent, animationComp *components.AnimationComponent) {
 
 		for identifier, handler := range animationComp.AnimationHandlers {
-			if handler(transform) {
+			if handler(transform) && animationComp.CurrentAnimation != identifier {
 				animationComp.CurrentAnimation = identifier
+				core.MarkChanged[*components.AnimationComponent](registry, entity)
 			}
 		}
 
@@ -57,5 +53,5 @@ func (as *AnimationSystem) Update(registry *core.Registry) {
 				currentAnimation.Counter = 0
 			}
 		}
-	}
+	})
 }
@@ -1,51 +1,78 @@
 package systems
 
 import (
-	"reflect"
+	"slices"
 
 	"github.com/Djosar/kro-ecs/lib/components"
 	"github.com/Djosar/kro-ecs/lib/core"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/Djosar/kro-ecs/lib/input"
+	"github.com/hajimehoshi/ebiten/v2"
 )
 
 // InputSystem is responsible for handling input within the entity-component-system (ECS) architecture.
 // It updates control components by detecting key presses and releases, maintaining a buffer of active controls.
-type InputSystem struct{}
+//
+// The input of every tick is polled from Source, which reads the keyboard by default. Replace it with
+// an input.ScriptedSource to run the system without a window, e.g. to replay a recording. If
+// Recording is set, the keys held down during every tick are appended to it.
+type InputSystem struct {
+	Source    input.Source
+	Recording *input.Recording
+	held      []ebiten.Key
+}
 
-// NewInputSystem creates and returns a new instance of InputSystem.
+// NewInputSystem creates and returns a new instance of InputSystem reading ebiten's input.
 //
 // Returns:
 //
 //	*InputSystem: A pointer to the newly created InputSystem instance.
 func NewInputSystem() *InputSystem {
-	return &InputSystem{}
+	return &InputSystem{
+		Source: input.NewEbitenSource(),
+	}
 }
 
 // Update iterates through all entities that have a ControlsComponent. It updates the input state
-// by detecting key presses and releases and maintaining a buffer of active controls.
+// by detecting key presses and releases and maintaining a buffer of active controls. Keys pressed
+// during the same tick are buffered in key order. Components whose buffer changed are marked as
+// changed.
 //
 // Parameters:
 //
 //	registry (*core.Registry): The registry containing all entities and components in the ECS.
-func (iss *InputSystem) Update(registry *core.Registry) {
-	controlsType := reflect.TypeOf(&components.ControlsComponent{})
-	for _, component := range registry.GetAllComponentsOfType(controlsType) {
-		controlsComponent := component.(*components.ControlsComponent)
-		for key := range controlsComponent.Controls {
-			if inpututil.IsKeyJustPressed(key) {
+//	commands (*core.Commands): The command buffer for structural changes.
+func (iss *InputSystem) Update(registry *core.Registry, commands *core.Commands) {
+	held := iss.Source.Poll().Keys
+	if iss.Recording != nil {
+		iss.Recording.Record(held)
+	}
+
+	core.Each(registry, func(entity core.Entity, controlsComponent *components.ControlsComponent) {
+		changed := false
+		for _, key := range held {
+			if _, ok := controlsComponent.Controls[key]; ok && !slices.Contains(iss.held, key) {
 				controlsComponent.ControlsBuffer = append(controlsComponent.ControlsBuffer, key)
+				changed = true
 			}
-			if inpututil.IsKeyJustReleased(key) {
-				releasedKeyIdx := -1
-				for idx, k := range controlsComponent.ControlsBuffer {
-					if k == key {
-						releasedKeyIdx = idx
-					}
-				}
-				if releasedKeyIdx >= 0 {
-					controlsComponent.ControlsBuffer = append(controlsComponent.ControlsBuffer[:releasedKeyIdx], controlsComponent.ControlsBuffer[releasedKeyIdx+1:]...)
+		}
+		for _, key := range iss.held {
+			if slices.Contains(held, key) {
+				continue
+			}
+			releasedKeyIdx := -1
+			for idx, k := range controlsComponent.ControlsBuffer {
+				if k == key {
+					releasedKeyIdx = idx
 				}
 			}
+			if releasedKeyIdx >= 0 {
+				controlsComponent.ControlsBuffer = append(controlsComponent.ControlsBuffer[:releasedKeyIdx], controlsComponent.ControlsBuffer[releasedKeyIdx+1:]...)
+				changed = true
+			}
 		}
-	}
+		if changed {
+			core.MarkChanged[*components.ControlsComponent](registry, entity)
+		}
+	})
+	iss.held = held
 }
@@ -0,0 +1,94 @@
+package systems
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Djosar/kro-ecs/lib/components"
+	"github.com/Djosar/kro-ecs/lib/core"
+	"github.com/Djosar/kro-ecs/lib/input"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// newControlledEntity creates a registry with one entity that moves right on D
+// and sprints on Shift.
+func newControlledEntity() (*core.Registry, core.Entity) {
+	registry := core.NewRegistry()
+	entity := registry.NewEntity()
+	core.Add(registry, entity, &components.TransformComponent{})
+	core.Add(registry, entity, &components.ControlsComponent{
+		Controls: map[ebiten.Key]func(*components.TransformComponent){
+			ebiten.KeyD:     components.ControlActions["move-right"],
+			ebiten.KeyShift: components.ControlActions["sprint"],
+		},
+	})
+	return registry, entity
+}
+
+// runInput updates an InputSystem reading source and a MovementSystem until the
+// source is exhausted and returns the final transform of the entity.
+func runInput(t *testing.T, source *input.ScriptedSource, recording *input.Recording) components.TransformComponent {
+	t.Helper()
+	registry, entity := newControlledEntity()
+	inputSystem := &InputSystem{Source: source, Recording: recording}
+	movementSystem := NewMovementSystem()
+	for !source.Done() {
+		inputSystem.Update(registry, nil)
+		movementSystem.Update(registry, nil)
+	}
+	transformComponent, ok := core.Get[*components.TransformComponent](registry, entity)
+	if !ok {
+		t.Fatal("entity lost its TransformComponent")
+	}
+	return *transformComponent
+}
+
+// TestInputReplayIsDeterministic checks that replaying a recorded session into a
+// fresh registry moves the entity to the same position.
+func TestInputReplayIsDeterministic(t *testing.T) {
+	source := input.NewScriptedSource(
+		input.State{Keys: []ebiten.Key{ebiten.KeyD}},
+		input.State{Keys: []ebiten.Key{ebiten.KeyD, ebiten.KeyShift}},
+		input.State{},
+		input.State{Keys: []ebiten.Key{ebiten.KeyShift, ebiten.KeyD}},
+		input.State{Keys: []ebiten.Key{ebiten.KeyShift}},
+	)
+	recording := input.NewRecording()
+	recorded := runInput(t, source, recording)
+	if recording.Len() != 5 {
+		t.Fatalf("recording.Len() = %d, want 5", recording.Len())
+	}
+
+	replayed := runInput(t, input.NewReplaySource(recording), nil)
+	if replayed.Position != recorded.Position {
+		t.Errorf("replayed Position = %v, want %v", replayed.Position, recorded.Position)
+	}
+	if recorded.Position.X == 0 {
+		t.Error("recorded session did not move the entity")
+	}
+}
+
+// TestInputSystemBuffersHeldKeys checks that newly held bound keys are appended
+// to the ControlsBuffer in press order, that released keys are removed and that
+// unbound keys are ignored.
+func TestInputSystemBuffersHeldKeys(t *testing.T) {
+	registry, entity := newControlledEntity()
+	inputSystem := &InputSystem{Source: input.NewScriptedSource(
+		input.State{Keys: []ebiten.Key{ebiten.KeyShift, ebiten.KeyX}},
+		input.State{Keys: []ebiten.Key{ebiten.KeyD, ebiten.KeyShift}},
+		input.State{Keys: []ebiten.Key{ebiten.KeyD}},
+	)}
+	want := [][]ebiten.Key{
+		{ebiten.KeyShift},
+		{ebiten.KeyShift, ebiten.KeyD},
+		{ebiten.KeyD},
+		{},
+	}
+	for tick, buffer := range want {
+		inputSystem.Update(registry, nil)
+		controlsComponent, _ := core.Get[*components.ControlsComponent](registry, entity)
+		if !slices.Equal(controlsComponent.ControlsBuffer, buffer) {
+			t.Errorf("ControlsBuffer after tick %d = %v, want %v", tick, controlsComponent.ControlsBuffer, buffer)
+		}
+	}
+}
@@ -1,8 +1,6 @@
 package systems
 
 import (
-	"reflect"
-
 	"github.com/Djosar/kro-ecs/lib/components"
 	"github.com/Djosar/kro-ecs/lib/core"
 )
@@ -10,7 +8,10 @@ import (
 // MovementSystem is responsible for updating the position and velocity
 // of entities within the entity-component-system (ECS) architecture.
 // It processes movement controls and applies the resulting transformations.
-type MovementSystem struct{}
+// Entities are processed in parallel batches of BatchSize.
+type MovementSystem struct {
+	BatchSize int
+}
 
 // NewMovementSystem creates and returns a new instance of MovementSystem.
 //
@@ -18,23 +19,23 @@ type MovementSystem struct{}
 //
 //	*MovementSystem: A pointer to the newly created MovementSystem instance.
 func NewMovementSystem() *MovementSystem {
-	return &MovementSystem{}
+	return &MovementSystem{
+		BatchSize: core.DefaultBatchSize,
+	}
 }
 
 // Update iterates through all entities that have both a TransformComponent and
 // a ControlsComponent. It updates the velocity and position of each entity based
-// on the current controls and applies the transformations.
+// on the current controls and applies the transformations. Transforms that moved
+// are marked as changed.
 //
 // Parameters:
 //
 //	registry (*core.Registry): The registry containing all entities and components in the ECS.
-func (ms *MovementSystem) Update(registry *core.Registry) {
-	ctrlType := reflect.TypeOf(&components.ControlsComponent{})
-
-	transfType := reflect.TypeOf(&components.TransformComponent{})
-	for entity, transf := range registry.GetAllComponentsOfType(transfType) {
-		transformComponent := transf.(*components.TransformComponent)
-		controls := registry.GetComponent(ctrlType, entity).(*components.ControlsComponent)
+//	commands (*core.Commands): The command buffer for structural changes.
+func (ms *MovementSystem) Update(registry *core.Registry, commands *core.Commands) {
+	query := core.NewQuery2[*components.TransformComponent, *components.ControlsComponent](registry)
+	query.ParallelForEach(ms.BatchSize, func(entity core.Entity, transformComponent *components.TransformComponent, controls *components.ControlsComponent) {
 		transformComponent.Velocity.DX = 0
 		transformComponent.Velocity.DY = 0
 		transformComponent.Speed = 1
@@ -45,7 +46,11 @@ func (ms *MovementSystem) Update(registry *core.Registry) {
 			}
 		}
 
+		if transformComponent.Velocity.DX == 0 && transformComponent.Velocity.DY == 0 {
+			return
+		}
 		transformComponent.Position.X += transformComponent.Speed * transformComponent.Velocity.DX
 		transformComponent.Position.Y += transformComponent.Speed * transformComponent.Velocity.DY
-	}
+		core.MarkChanged[*components.TransformComponent](registry, entity)
+	})
 }
@@ -0,0 +1,46 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/Djosar/kro-ecs/lib/input"
+	"github.com/Djosar/kro-ecs/lib/util"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TestMovementSystemSprint checks that the entity moves one unit per tick while
+// moving and two while sprinting, and that sprinting alone does not move it.
+func TestMovementSystemSprint(t *testing.T) {
+	tests := []struct {
+		name   string
+		states []input.State
+		want   util.Coordinate[float32]
+	}{
+		{
+			name:   "idle",
+			states: []input.State{{}, {}},
+		},
+		{
+			name:   "walk",
+			states: []input.State{{Keys: []ebiten.Key{ebiten.KeyD}}, {Keys: []ebiten.Key{ebiten.KeyD}}},
+			want:   util.Coordinate[float32]{X: 2},
+		},
+		{
+			name:   "sprint",
+			states: []input.State{{Keys: []ebiten.Key{ebiten.KeyD, ebiten.KeyShift}}, {Keys: []ebiten.Key{ebiten.KeyD}}},
+			want:   util.Coordinate[float32]{X: 3},
+		},
+		{
+			name:   "sprint without moving",
+			states: []input.State{{Keys: []ebiten.Key{ebiten.KeyShift}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transformComponent := runInput(t, input.NewScriptedSource(test.states...), nil)
+			if transformComponent.Position != test.want {
+				t.Errorf("Position = %v, want %v", transformComponent.Position, test.want)
+			}
+		})
+	}
+}
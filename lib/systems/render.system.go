@@ -1,8 +1,6 @@
 package systems
 
 import (
-	"reflect"
-
 	"github.com/Djosar/kro-ecs/lib/components"
 	"github.com/Djosar/kro-ecs/lib/core"
 	"github.com/hajimehoshi/ebiten/v2"
@@ -10,9 +8,7 @@ import (
 
 // RenderSystem is responsible for rendering entities within the entity-component-system (ECS) architecture.
 // It draws the current animation frame of each entity onto the screen.
-type RenderSystem struct {
-	Screen *ebiten.Image
-}
+type RenderSystem struct{}
 
 // NewRenderSystem creates and returns a new instance of RenderSystem.
 //
@@ -23,27 +19,28 @@ func NewRenderSystem() *RenderSystem {
 	return &RenderSystem{}
 }
 
-// Update iterates through all entities that have both a TransformComponent and
-// an AnimationComponent. It renders the current frame of the entity's animation
-// to the screen based on the entity's position.
+// Draw iterates through all entities that have both a GlobalTransformComponent
+// and an AnimationComponent. It renders the current frame of the entity's
+// animation to the screen based on the entity's world-space position. Screens
+// other than an *ebiten.Image are ignored.
 //
 // Parameters:
 //
 //	registry (*core.Registry): The registry containing all entities and components in the ECS.
-func (rs *RenderSystem) Update(registry *core.Registry) {
-	transfType := reflect.TypeOf(&components.TransformComponent{})
-	animType := reflect.TypeOf(&components.AnimationComponent{})
-
-	for entity, p := range registry.GetAllComponentsOfType(transfType) {
-		transf := p.(*components.TransformComponent)
-		animationComp := registry.GetComponent(animType, entity).(*components.AnimationComponent)
+//	screen (core.Screen): The *ebiten.Image the frames are drawn onto.
+func (rs *RenderSystem) Draw(registry *core.Registry, screen core.Screen) {
+	target, ok := screen.(*ebiten.Image)
+	if !ok {
+		return
+	}
+	query := core.NewQuery2[*components.GlobalTransformComponent, *components.AnimationComponent](registry)
+	query.Each(func(_ core.Entity, transf *components.GlobalTransformComponent, animationComp *components.AnimationComponent) {
 		currentAnimation := animationComp.GetCurrentAnimation()
 		if currentAnimation != nil {
 			opts := &ebiten.DrawImageOptions{}
 			opts.GeoM.Translate(float64(transf.Position.X), float64(transf.Position.Y))
 			currentFrame := currentAnimation.GetCurrentFrame()
-			rs.Screen.DrawImage(currentFrame, opts)
+			target.DrawImage(currentFrame, opts)
 		}
-	}
-
+	})
 }
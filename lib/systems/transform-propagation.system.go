@@ -0,0 +1,83 @@
+package systems
+
+import (
+	"github.com/Djosar/kro-ecs/lib/components"
+	"github.com/Djosar/kro-ecs/lib/core"
+	"github.com/Djosar/kro-ecs/lib/util"
+)
+
+// TransformPropagationSystem computes the world-space position of every entity
+// with a TransformComponent by walking the entity hierarchy from the root
+// entities down, adding the local positions of all ancestors. Root entities
+// without a TransformComponent are treated as placed at the origin, so that their
+// descendants are positioned nonetheless.
+type TransformPropagationSystem struct{}
+
+// NewTransformPropagationSystem creates and returns a new instance of TransformPropagationSystem.
+//
+// Returns:
+//
+//	*TransformPropagationSystem: A pointer to the newly created TransformPropagationSystem instance.
+func NewTransformPropagationSystem() *TransformPropagationSystem {
+	return &TransformPropagationSystem{}
+}
+
+// Update updates the GlobalTransformComponent of every entity with a
+// TransformComponent and of every descendant of a root entity. Entities without a
+// GlobalTransformComponent get one through the command buffer.
+//
+// Parameters:
+//
+//	registry (*core.Registry): The registry containing all entities and components in the ECS.
+//	commands (*core.Commands): The command buffer for structural changes.
+func (tps *TransformPropagationSystem) Update(registry *core.Registry, commands *core.Commands) {
+	roots := core.NewQuery1[*components.TransformComponent](registry, core.Without[core.Parent]())
+	roots.Each(func(entity core.Entity, transform *components.TransformComponent) {
+		tps.propagate(registry, commands, entity, transform.Position)
+	})
+	untransformedRoots := core.NewQuery1[core.Children](registry, core.Without[core.Parent](), core.Without[*components.TransformComponent]())
+	untransformedRoots.Each(func(entity core.Entity, children core.Children) {
+		tps.propagateChildren(registry, commands, children, util.Coordinate[float32]{})
+	})
+}
+
+// propagate stores the world-space position of an entity and continues with its
+// children.
+//
+// Parameters:
+//
+//	registry (*core.Registry): The registry containing all entities and components in the ECS.
+//	commands (*core.Commands): The command buffer for structural changes.
+//	entity (core.Entity): The entity whose position is stored.
+//	position (util.Coordinate[float32]): The world-space position of the entity.
+func (tps *TransformPropagationSystem) propagate(registry *core.Registry, commands *core.Commands, entity core.Entity, position util.Coordinate[float32]) {
+	if global, ok := core.Get[*components.GlobalTransformComponent](registry, entity); ok {
+		global.Position = position
+	} else {
+		core.AddDeferred(commands, entity, &components.GlobalTransformComponent{Position: position})
+	}
+
+	if children, ok := core.Get[core.Children](registry, entity); ok {
+		tps.propagateChildren(registry, commands, children, position)
+	}
+}
+
+// propagateChildren stores the world-space positions of the children of an
+// entity and continues with their descendants.
+//
+// Parameters:
+//
+//	registry (*core.Registry): The registry containing all entities and components in the ECS.
+//	commands (*core.Commands): The command buffer for structural changes.
+//	children (core.Children): The children of the entity.
+//	position (util.Coordinate[float32]): The world-space position of the entity.
+func (tps *TransformPropagationSystem) propagateChildren(registry *core.Registry, commands *core.Commands, children core.Children, position util.Coordinate[float32]) {
+	for _, child := range children.Entities {
+		childPosition := position
+		if transform, ok := core.Get[*components.TransformComponent](registry, child); ok {
+			childPosition.X += transform.Position.X
+			childPosition.Y += transform.Position.Y
+		}
+		tps.propagate(registry, commands, child, childPosition)
+	}
+}
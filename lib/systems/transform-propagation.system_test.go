@@ -0,0 +1,77 @@
+package systems
+
+import (
+	"testing"
+
+	"github.com/Djosar/kro-ecs/lib/components"
+	"github.com/Djosar/kro-ecs/lib/core"
+	"github.com/Djosar/kro-ecs/lib/util"
+)
+
+// newPositioned creates an entity with a TransformComponent at the given local
+// position, attached to parent unless it is core.NilEntity.
+func newPositioned(t *testing.T, registry *core.Registry, parent core.Entity, x, y float32) core.Entity {
+	t.Helper()
+	entity := registry.NewEntity()
+	core.Add(registry, entity, &components.TransformComponent{Position: util.Coordinate[float32]{X: x, Y: y}})
+	if parent != core.NilEntity {
+		if err := registry.SetParent(entity, parent); err != nil {
+			t.Fatalf("SetParent() error = %v", err)
+		}
+	}
+	return entity
+}
+
+// TestTransformPropagation checks the global positions of nested entities, both
+// when the GlobalTransformComponent is added through the command buffer and when
+// it is updated in place, including the children of a root without a transform.
+func TestTransformPropagation(t *testing.T) {
+	registry := core.NewRegistry()
+	if err := registry.AddSystem("transform-propagation", NewTransformPropagationSystem()); err != nil {
+		t.Fatalf("AddSystem() error = %v", err)
+	}
+	root := newPositioned(t, registry, core.NilEntity, 10, 0)
+	child := newPositioned(t, registry, root, 1, 2)
+	grandchild := newPositioned(t, registry, child, 3, 0)
+	anchor := registry.NewEntity()
+	anchored := newPositioned(t, registry, anchor, 5, 5)
+
+	globalOf := func(entity core.Entity) (util.Coordinate[float32], bool) {
+		global, ok := core.Get[*components.GlobalTransformComponent](registry, entity)
+		if !ok {
+			return util.Coordinate[float32]{}, false
+		}
+		return global.Position, true
+	}
+	check := func(frame string, want map[core.Entity]util.Coordinate[float32]) {
+		t.Helper()
+		for entity, position := range want {
+			if got, ok := globalOf(entity); !ok || got != position {
+				t.Errorf("%s: global position of %v = %v, %v, want %v", frame, entity, got, ok, position)
+			}
+		}
+	}
+
+	if err := registry.RunPhase(core.PhaseUpdate); err != nil {
+		t.Fatalf("RunPhase() error = %v", err)
+	}
+	check("first frame", map[core.Entity]util.Coordinate[float32]{
+		root:       {X: 10, Y: 0},
+		child:      {X: 11, Y: 2},
+		grandchild: {X: 14, Y: 2},
+		anchored:   {X: 5, Y: 5},
+	})
+	if _, ok := globalOf(anchor); ok {
+		t.Error("root without a transform got a GlobalTransformComponent")
+	}
+
+	transform, _ := core.Get[*components.TransformComponent](registry, root)
+	transform.Position.Y = 4
+	registry.RunPhase(core.PhaseUpdate)
+	check("second frame", map[core.Entity]util.Coordinate[float32]{
+		root:       {X: 10, Y: 4},
+		child:      {X: 11, Y: 6},
+		grandchild: {X: 14, Y: 6},
+		anchored:   {X: 5, Y: 5},
+	})
+}
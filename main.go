@@ -1,13 +1,18 @@
 package main
 
 import (
+	"flag"
 	"log"
 
 	"github.com/Djosar/kro-ecs/app/game"
+	"github.com/Djosar/kro-ecs/lib/input"
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
+	recordPath := flag.String("record", "", "write the input of the session to this file on exit")
+	replayPath := flag.String("replay", "", "replay the input recorded in this file")
+	flag.Parse()
 
 	game, err := game.NewGame()
 	if err != nil {
@@ -15,10 +20,29 @@ func main() {
 		return
 	}
 
+	if *replayPath != "" {
+		replay, err := input.LoadRecordingFile(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+			return
+		}
+		game.ReplayInput(replay)
+	}
+	var recording *input.Recording
+	if *recordPath != "" {
+		recording = game.RecordInput()
+	}
+
 	ebiten.SetWindowSize(640, 480)
 	ebiten.SetWindowTitle("Your game's title")
 	if err := ebiten.RunGame(game); err != nil {
 		log.Fatal(err)
 		return
 	}
+
+	if recording != nil {
+		if err := recording.SaveFile(*recordPath); err != nil {
+			log.Fatal(err)
+		}
+	}
 }